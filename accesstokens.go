@@ -0,0 +1,111 @@
+package vrm
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+type AccessToken struct {
+	ID        string `json:"idAccessToken"`
+	Name      string `json:"name"`
+	CreatedOn int64  `json:"createdOn"`
+	Scope     string `json:"scope"`
+	Expires   *int64 `json:"expires"`
+	LastSeen  *int64 `json:"lastSeen,omitempty"`
+}
+
+type AccessTokensResponse struct {
+	Success bool          `json:"success"`
+	Tokens  []AccessToken `json:"tokens"`
+}
+
+// List all personal access tokens of the session's user
+func (s *vrmSession) ListAccessTokens() (*AccessTokensResponse, error) {
+	return s.ListAccessTokensContext(context.Background())
+}
+
+// ListAccessTokensContext is like ListAccessTokens but carries a context.
+func (s *vrmSession) ListAccessTokensContext(ctx context.Context) (*AccessTokensResponse, error) {
+	url, err := s.formatURL(accessTokensListURL, URLParams{
+		"UserID": strconv.Itoa(s.UserID()),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := AccessTokensResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+type CreateAccessTokenRequest struct {
+	Name   string `json:"name"`
+	Expiry int64  `json:"expiry,omitempty"`
+}
+
+type CreateAccessTokenResponse struct {
+	Success       bool   `json:"success"`
+	Token         string `json:"token"`
+	AccessTokenID string `json:"idAccessToken"`
+}
+
+// Create a new personal access token for the session's user. A zero expiry creates a token that never expires.
+func (s *vrmSession) CreateAccessToken(name string, expiry time.Time) (*CreateAccessTokenResponse, error) {
+	return s.CreateAccessTokenContext(context.Background(), name, expiry)
+}
+
+// CreateAccessTokenContext is like CreateAccessToken but carries a context.
+func (s *vrmSession) CreateAccessTokenContext(ctx context.Context, name string, expiry time.Time) (*CreateAccessTokenResponse, error) {
+	url, err := s.formatURL(accessTokensCreateURL, URLParams{
+		"UserID": strconv.Itoa(s.UserID()),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req := CreateAccessTokenRequest{Name: name}
+	if !expiry.IsZero() {
+		req.Expiry = expiry.Unix()
+	}
+
+	data := CreateAccessTokenResponse{}
+	if err := s.postAndLoad(ctx, req, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+type RevokeAccessTokenResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Removed int `json:"removed"`
+	} `json:"data"`
+}
+
+// Revoke the personal access token with the given ID
+func (s *vrmSession) RevokeAccessToken(accessTokenID string) (*RevokeAccessTokenResponse, error) {
+	return s.RevokeAccessTokenContext(context.Background(), accessTokenID)
+}
+
+// RevokeAccessTokenContext is like RevokeAccessToken but carries a context.
+func (s *vrmSession) RevokeAccessTokenContext(ctx context.Context, accessTokenID string) (*RevokeAccessTokenResponse, error) {
+	url, err := s.formatURL(accessTokensRevokeURL, URLParams{
+		"UserID":        strconv.Itoa(s.UserID()),
+		"accessTokenID": accessTokenID,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := RevokeAccessTokenResponse{}
+	if err := s.getOnceAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
@@ -0,0 +1,96 @@
+package vrm_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestAccessTokensData(t *testing.T) {
+	x := []byte(`{
+		"success": true,
+		"tokens": [{
+			"idAccessToken": "1234",
+			"name": "provisioning",
+			"createdOn": 1600000000,
+			"scope": "all",
+			"expires": null
+		},
+		{
+			"idAccessToken": "1235",
+			"name": "collector",
+			"createdOn": 1600000100,
+			"scope": "all",
+			"expires": 1700000000
+		}]
+	}`)
+	tokens := vrm.AccessTokensResponse{}
+	err := json.NewDecoder(bytes.NewBuffer(x)).Decode(&tokens)
+	if assert.NoError(t, err) {
+		assert.True(t, tokens.Success)
+		assert.Len(t, tokens.Tokens, 2)
+		assert.Equal(t, "1234", tokens.Tokens[0].ID)
+		assert.Nil(t, tokens.Tokens[0].Expires)
+		if assert.NotNil(t, tokens.Tokens[1].Expires) {
+			assert.Equal(t, int64(1700000000), *tokens.Tokens[1].Expires)
+		}
+	}
+}
+
+func TestAccessTokens(t *testing.T) {
+	var bodies []string
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/22/accesstokens/list":
+			assert.Equal(t, http.MethodGet, r.Method)
+			fmt.Fprint(w, `{"success": true, "tokens": [{"idAccessToken": "7", "name": "collector", "scope": "all"}]}`)
+		case "/users/22/accesstokens/create":
+			assert.Equal(t, http.MethodPost, r.Method)
+			body, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			bodies = append(bodies, string(body))
+			fmt.Fprint(w, `{"success": true, "token": "secret", "idAccessToken": "8"}`)
+		case "/users/22/accesstokens/8/revoke":
+			assert.Equal(t, http.MethodGet, r.Method)
+			fmt.Fprint(w, `{"success": true, "data": {"removed": 1}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tokens, err := session.ListAccessTokens()
+	if assert.NoError(t, err) && assert.Len(t, tokens.Tokens, 1) {
+		assert.Equal(t, "collector", tokens.Tokens[0].Name)
+	}
+
+	created, err := session.CreateAccessToken("provisioning", time.Time{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "secret", created.Token)
+		assert.Equal(t, "8", created.AccessTokenID)
+	}
+	_, err = session.CreateAccessToken("expiring", time.Unix(1700000000, 0))
+	assert.NoError(t, err)
+	if assert.Len(t, bodies, 2) {
+		assert.JSONEq(t, `{"name": "provisioning"}`, bodies[0], "a zero expiry is left out")
+		assert.JSONEq(t, `{"name": "expiring", "expiry": 1700000000}`, bodies[1])
+	}
+
+	revoked, err := session.RevokeAccessToken("8")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, revoked.Data.Removed)
+	}
+}
@@ -0,0 +1,181 @@
+package vrm
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AlarmRule configures when an alarm is raised for a data attribute of a device instance.
+// A rule is identified by its data attribute and instance. Unset thresholds are nil.
+type AlarmRule struct {
+	DataAttributeID     int      `json:"idDataAttribute"`
+	Instance            int      `json:"instance"`
+	Code                string   `json:"code,omitempty"`
+	Description         string   `json:"description,omitempty"`
+	Enabled             bool     `json:"AlarmEnabled"`
+	NotifyAfterSeconds  int      `json:"NotifyAfterSeconds"`
+	LowAlarm            *float64 `json:"lowAlarm"`
+	LowAlarmHysteresis  float64  `json:"lowAlarmHysteresis"`
+	HighAlarm           *float64 `json:"highAlarm"`
+	HighAlarmHysteresis float64  `json:"highAlarmHysteresis"`
+}
+
+type AlarmRulesResponse struct {
+	Success bool        `json:"success"`
+	Rules   []AlarmRule `json:"alarms"`
+}
+
+// List the alarm rules configured for an installation
+func (s *vrmSession) AlarmRules(siteID int) (*AlarmRulesResponse, error) {
+	return s.AlarmRulesContext(context.Background(), siteID)
+}
+
+// AlarmRulesContext is like AlarmRules but carries a context.
+func (s *vrmSession) AlarmRulesContext(ctx context.Context, siteID int) (*AlarmRulesResponse, error) {
+	url, err := s.formatURL(alarmsURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := AlarmRulesResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+type AlarmRuleResponse struct {
+	Success bool `json:"success"`
+}
+
+// Add an alarm rule to an installation
+func (s *vrmSession) CreateAlarmRule(siteID int, rule AlarmRule) (*AlarmRuleResponse, error) {
+	return s.CreateAlarmRuleContext(context.Background(), siteID, rule)
+}
+
+// CreateAlarmRuleContext is like CreateAlarmRule but carries a context.
+func (s *vrmSession) CreateAlarmRuleContext(ctx context.Context, siteID int, rule AlarmRule) (*AlarmRuleResponse, error) {
+	return s.changeAlarmRule(ctx, http.MethodPost, siteID, rule)
+}
+
+// Replace the alarm rule with the same data attribute and instance
+func (s *vrmSession) UpdateAlarmRule(siteID int, rule AlarmRule) (*AlarmRuleResponse, error) {
+	return s.UpdateAlarmRuleContext(context.Background(), siteID, rule)
+}
+
+// UpdateAlarmRuleContext is like UpdateAlarmRule but carries a context.
+func (s *vrmSession) UpdateAlarmRuleContext(ctx context.Context, siteID int, rule AlarmRule) (*AlarmRuleResponse, error) {
+	return s.changeAlarmRule(ctx, http.MethodPut, siteID, rule)
+}
+
+// Remove the alarm rule of a data attribute and instance
+func (s *vrmSession) DeleteAlarmRule(siteID int, dataAttributeID int, instance int) (*AlarmRuleResponse, error) {
+	return s.DeleteAlarmRuleContext(context.Background(), siteID, dataAttributeID, instance)
+}
+
+// DeleteAlarmRuleContext is like DeleteAlarmRule but carries a context.
+func (s *vrmSession) DeleteAlarmRuleContext(ctx context.Context, siteID int, dataAttributeID int, instance int) (*AlarmRuleResponse, error) {
+	return s.changeAlarmRule(ctx, http.MethodDelete, siteID, struct {
+		DataAttributeID int `json:"idDataAttribute"`
+		Instance        int `json:"instance"`
+	}{dataAttributeID, instance})
+}
+
+func (s *vrmSession) changeAlarmRule(ctx context.Context, method string, siteID int, reqData interface{}) (*AlarmRuleResponse, error) {
+	url, err := s.formatURL(alarmsURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := AlarmRuleResponse{}
+	if err := s.sendAndLoad(ctx, method, reqData, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// Alarm is an alarm raised for an installation. Cleared is 0 while the alarm is active.
+type Alarm struct {
+	AlarmID         int     `json:"idAlarm"`
+	DataAttributeID int     `json:"idDataAttribute"`
+	Instance        int     `json:"instance"`
+	Code            string  `json:"code"`
+	Description     string  `json:"description"`
+	Device          string  `json:"device"`
+	Type            string  `json:"type"`
+	Value           float64 `json:"value"`
+	FormattedValue  string  `json:"formattedValue"`
+	Started         int64   `json:"started"`
+	Cleared         int64   `json:"cleared"`
+}
+
+// Active reports whether the alarm has not been cleared yet.
+func (a *Alarm) Active() bool {
+	return a.Cleared == 0
+}
+
+// AlarmQuery selects the alarms of a period. Zero values are left to VRM's defaults.
+type AlarmQuery struct {
+	Start time.Time
+	End   time.Time
+	// Only return alarms which have not been cleared
+	ActiveOnly bool
+}
+
+type AlarmsResponse struct {
+	Success bool    `json:"success"`
+	Alarms  []Alarm `json:"records"`
+}
+
+// List active and historic alarms of an installation
+func (s *vrmSession) Alarms(siteID int, query AlarmQuery) (*AlarmsResponse, error) {
+	return s.AlarmsContext(context.Background(), siteID, query)
+}
+
+// AlarmsContext is like Alarms but carries a context.
+func (s *vrmSession) AlarmsContext(ctx context.Context, siteID int, query AlarmQuery) (*AlarmsResponse, error) {
+	values := struct {
+		Start  int64 `url:"start,omitempty"`
+		End    int64 `url:"end,omitempty"`
+		Active bool  `url:"active,int,omitempty"`
+	}{Active: query.ActiveOnly}
+	if !query.Start.IsZero() {
+		values.Start = query.Start.Unix()
+	}
+	if !query.End.IsZero() {
+		values.End = query.End.Unix()
+	}
+
+	url, err := s.formatURL(alarmLogURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, values)
+	if err != nil {
+		return nil, err
+	}
+
+	data := AlarmsResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	if query.ActiveOnly {
+		// Don't rely on the server applying the filter
+		active := data.Alarms[:0]
+		for _, alarm := range data.Alarms {
+			if alarm.Active() {
+				active = append(active, alarm)
+			}
+		}
+		data.Alarms = active
+	}
+
+	return &data, nil
+}
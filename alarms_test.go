@@ -0,0 +1,66 @@
+package vrm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestAlarmRules(t *testing.T) {
+	var changes []string
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/installations/1234/alarms" && r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"success": true, "alarms": [{
+				"idDataAttribute": 51, "instance": 288, "code": "bs", "description": "State of charge",
+				"AlarmEnabled": true, "NotifyAfterSeconds": 60,
+				"lowAlarm": 20, "lowAlarmHysteresis": 5, "highAlarm": null, "highAlarmHysteresis": 0
+			}]}`)
+		case r.URL.Path == "/installations/1234/alarms":
+			rule := vrm.AlarmRule{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&rule))
+			changes = append(changes, fmt.Sprintf("%s %d/%d", r.Method, rule.DataAttributeID, rule.Instance))
+			fmt.Fprint(w, `{"success": true}`)
+		case r.URL.Path == "/installations/1234/alarm-log":
+			assert.Equal(t, "1", r.URL.Query().Get("active"))
+			fmt.Fprint(w, `{"success": true, "records": [
+				{"idAlarm": 1, "idDataAttribute": 51, "instance": 288, "description": "State of charge", "started": 1600000000, "cleared": 1600003600},
+				{"idAlarm": 2, "idDataAttribute": 47, "instance": 288, "description": "Voltage", "started": 1600007200, "cleared": null}
+			]}`)
+		}
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rules, err := session.AlarmRules(1234)
+	if assert.NoError(t, err) && assert.Len(t, rules.Rules, 1) {
+		rule := rules.Rules[0]
+		assert.True(t, rule.Enabled)
+		if assert.NotNil(t, rule.LowAlarm) {
+			assert.Equal(t, float64(20), *rule.LowAlarm)
+		}
+		assert.Nil(t, rule.HighAlarm)
+	}
+
+	low := 11.5
+	_, err = session.CreateAlarmRule(1234, vrm.AlarmRule{DataAttributeID: 47, Instance: 288, Enabled: true, LowAlarm: &low})
+	assert.NoError(t, err)
+	_, err = session.UpdateAlarmRule(1234, vrm.AlarmRule{DataAttributeID: 51, Instance: 288})
+	assert.NoError(t, err)
+	_, err = session.DeleteAlarmRule(1234, 51, 288)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"POST 47/288", "PUT 51/288", "DELETE 51/288"}, changes)
+
+	alarms, err := session.Alarms(1234, vrm.AlarmQuery{ActiveOnly: true})
+	if assert.NoError(t, err) && assert.Len(t, alarms.Alarms, 1) {
+		assert.Equal(t, 2, alarms.Alarms[0].AlarmID)
+	}
+}
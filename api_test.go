@@ -3,18 +3,22 @@ package vrm_test
 import (
 	"bytes"
 	"encoding/json"
-	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
-	"victron/vrm"
+	vrm "github.com/christianschmizz/go-victron"
+	"github.com/christianschmizz/go-victron/vrmtest"
 )
 
 func TestLoginAsDemo(t *testing.T) {
-	session, err := vrm.LoginAsDemo()
+	server := vrmtest.NewServer("vrmtest/testdata")
+	defer server.Close()
+
+	session, err := vrm.LoginAsDemo(server.Options()...)
 	if assert.NoError(t, err) {
-		users, err := session.Installations(session.UserID)
+		assert.Equal(t, vrm.DemoUserID, session.UserID())
+		users, err := session.Installations(session.UserID())
 		if assert.NoError(t, err) {
 			assert.True(t, users.Success)
 		}
@@ -22,14 +26,20 @@ func TestLoginAsDemo(t *testing.T) {
 }
 
 func TestDemoInstallations(t *testing.T) {
-	session, err := vrm.LoginAsDemo()
+	server := vrmtest.NewServer("vrmtest/testdata")
+	defer server.Close()
+
+	session, err := vrm.LoginAsDemo(server.Options()...)
 	if assert.NoError(t, err) {
 		installs, err := session.Installations(vrm.DemoUserID)
 		if assert.NoError(t, err) {
 			assert.True(t, installs.Success)
-			assert.Len(t, installs.Records, 36)
+			assert.Len(t, installs.Records, 2)
+
+			// Only the boat has diagnostics fixtures
+			diagnostics := map[int]int{1234: 3}
 			for _, record := range installs.Records {
-				siteID := strconv.Itoa(int(record.SiteID))
+				siteID := record.SiteID
 
 				overview, err := session.SystemOverview(siteID)
 				if assert.NoError(t, err) {
@@ -37,11 +47,19 @@ func TestDemoInstallations(t *testing.T) {
 				}
 
 				diag, err := session.Diagnostics(siteID, 1000)
-				if assert.NoError(t, err) {
+				if records, ok := diagnostics[siteID]; !ok {
+					assert.True(t, vrm.IsNotFound(err), "site %d", siteID)
+				} else if assert.NoError(t, err) {
 					assert.True(t, diag.Success)
+					assert.Len(t, diag.Records, records)
 				}
 			}
 		}
+
+		diag, err := session.Diagnostics(9012, 1000)
+		if assert.NoError(t, err) && assert.Len(t, diag.Records, 1) {
+			assert.Equal(t, "Gateway", diag.Records[0].Device)
+		}
 	}
 }
 
@@ -155,7 +173,9 @@ func TestStatsData(t *testing.T) {
 	err := json.NewDecoder(bytes.NewBuffer(x)).Decode(&stats)
 	if assert.NoError(t, err) {
 		assert.True(t, stats.Success)
-		assert.Equal(t, 12, len(stats.Records.Pc))
+		assert.Equal(t, 12, len(stats.Records["Pc"]))
+		assert.Equal(t, int64(1441066216), stats.Records["Pc"][0].Time.Unix())
+		assert.Equal(t, 12.927161, stats.Records["Pc"][0].Value)
 		assert.Equal(t, float64(2.5122129), stats.Totals.Pb)
 		assert.Equal(t, float64(513.2720223), stats.Totals.Kwh)
 	}
@@ -0,0 +1,76 @@
+package vrm
+
+//go:generate go run ./cmd/vrm-attributes -catalogue attributes.json -out attributes_gen.go
+
+import "sync"
+
+type DataAttributeEnum struct {
+	Value int    `json:"value"`
+	Name  string `json:"name"`
+}
+
+// DataAttribute describes a value logged by VRM, as referred to by diagnostics, stats and widgets.
+type DataAttribute struct {
+	ID          uint                `json:"id"`
+	Code        string              `json:"code,omitempty"`
+	Description string              `json:"description"`
+	Unit        string              `json:"unit,omitempty"`
+	DeviceClass string              `json:"deviceClass,omitempty"`
+	ServiceType string              `json:"serviceType,omitempty"`
+	EnumValues  []DataAttributeEnum `json:"enumValues,omitempty"`
+}
+
+// EnumName resolves an enum value of the attribute to its name.
+func (a *DataAttribute) EnumName(value int) (string, bool) {
+	for _, e := range a.EnumValues {
+		if e.Value == value {
+			return e.Name, true
+		}
+	}
+	return "", false
+}
+
+var (
+	dataAttributesOnce   sync.Once
+	dataAttributesByID   map[uint]*DataAttribute
+	dataAttributesByCode map[string]*DataAttribute
+)
+
+func indexDataAttributes() {
+	dataAttributesByID = make(map[uint]*DataAttribute, len(dataAttributes))
+	dataAttributesByCode = make(map[string]*DataAttribute, len(dataAttributes))
+	for i := range dataAttributes {
+		a := &dataAttributes[i]
+		dataAttributesByID[a.ID] = a
+		if len(a.Code) > 0 {
+			dataAttributesByCode[a.Code] = a
+		}
+	}
+}
+
+// DataAttributes returns the catalogue of known data attributes ordered by ID. The catalogue is not the
+// full list of VRM but holds the attributes merged from diagnostics by vrm-attributes so far, seeded with
+// the dumps in cmd/vrm-attributes/testdata; refresh it from live installations to learn about further ones.
+func DataAttributes() []DataAttribute {
+	return append([]DataAttribute(nil), dataAttributes...)
+}
+
+// LookupDataAttribute returns the catalogue entry of a data attribute ID.
+func LookupDataAttribute(id uint) (DataAttribute, bool) {
+	dataAttributesOnce.Do(indexDataAttributes)
+	a, ok := dataAttributesByID[id]
+	if !ok {
+		return DataAttribute{}, false
+	}
+	return *a, true
+}
+
+// LookupDataAttributeCode returns the catalogue entry of a data attribute code, e.g. "bv".
+func LookupDataAttributeCode(code string) (DataAttribute, bool) {
+	dataAttributesOnce.Do(indexDataAttributes)
+	a, ok := dataAttributesByCode[code]
+	if !ok {
+		return DataAttribute{}, false
+	}
+	return *a, true
+}
@@ -0,0 +1,218 @@
+// Code generated by vrm-attributes from attributes.json; DO NOT EDIT.
+
+package vrm
+
+// Version of the data attribute catalogue
+const DataAttributesVersion = "2026-10-18"
+
+// IDs of known data attributes
+const (
+	// Gateway: gatewayID (g)
+	AttrGatewayGatewayID uint = 1
+	// Battery Monitor: Voltage (bv)
+	AttrBatteryMonitorVoltage uint = 47
+	// Battery Monitor: Current (bc)
+	AttrBatteryMonitorCurrent uint = 49
+	// Battery Monitor: Consumed Amphours (ca)
+	AttrBatteryMonitorConsumedAmphours uint = 50
+	// Battery Monitor: State of charge (bs)
+	AttrBatteryMonitorStateOfCharge uint = 51
+	// Battery Monitor: Time to go (tg)
+	AttrBatteryMonitorTimeToGo uint = 52
+	// Battery Monitor: Deepest discharge (H1)
+	AttrBatteryMonitorDeepestDischarge uint = 58
+	// Battery Monitor: Last discharge (H2)
+	AttrBatteryMonitorLastDischarge uint = 59
+	// Battery Monitor: Average discharge (H3)
+	AttrBatteryMonitorAverageDischarge uint = 60
+	// Battery Monitor: Charge cycles (H4)
+	AttrBatteryMonitorChargeCycles uint = 61
+	// Battery Monitor: Full discharges (H5)
+	AttrBatteryMonitorFullDischarges uint = 62
+	// Battery Monitor: Total Ah drawn (H6)
+	AttrBatteryMonitorTotalAhDrawn uint = 63
+	// Battery Monitor: Minimum voltage (H7)
+	AttrBatteryMonitorMinimumVoltage uint = 64
+	// Battery Monitor: Maximum voltage (H8)
+	AttrBatteryMonitorMaximumVoltage uint = 65
+	// Battery Monitor: Time since last full charge (H9)
+	AttrBatteryMonitorTimeSinceLastFullCharge uint = 66
+	// Battery Monitor: Discharged energy (H17)
+	AttrBatteryMonitorDischargedEnergy uint = 69
+	// Battery Monitor: Charged energy (H18)
+	AttrBatteryMonitorChargedEnergy uint = 70
+	// Solar Charger: Battery voltage (ScV)
+	AttrSolarChargerBatteryVoltage uint = 81
+	// Solar Charger: Battery current (ScI)
+	AttrSolarChargerBatteryCurrent uint = 82
+	// Solar Charger: Charge state (ScS)
+	AttrSolarChargerChargeState uint = 85
+	// Solar Charger: PV voltage (PVV)
+	AttrSolarChargerPVVoltage uint = 86
+	// Solar Charger: Yield today (YT)
+	AttrSolarChargerYieldToday uint = 94
+	// Solar Charger: Yield yesterday (YY)
+	AttrSolarChargerYieldYesterday uint = 96
+	// Solar Charger: Error code (ScERR)
+	AttrSolarChargerErrorCode uint = 97
+	// Solar Charger: PV power (PVP)
+	AttrSolarChargerPVPower uint = 107
+	// Battery Monitor: Battery temperature (bT)
+	AttrBatteryMonitorBatteryTemperature uint = 115
+	// Battery Monitor: Minimum cell voltage (mcV)
+	AttrBatteryMonitorMinimumCellVoltage uint = 173
+	// Battery Monitor: Maximum cell voltage (McV)
+	AttrBatteryMonitorMaximumCellVoltage uint = 174
+	// Battery Monitor: Minimum cell temperature (mcT)
+	AttrBatteryMonitorMinimumCellTemperature uint = 175
+	// Battery Monitor: Maximum cell temperature (McT)
+	AttrBatteryMonitorMaximumCellTemperature uint = 176
+	// Battery Monitor: Allow to charge (Bac)
+	AttrBatteryMonitorAllowToCharge uint = 177
+	// Battery Monitor: Allow to discharge (Bad)
+	AttrBatteryMonitorAllowToDischarge uint = 178
+	// PV Inverter: Power L1 (pP1)
+	AttrPVInverterPowerL1 uint = 204
+	// PV Inverter: Power L2 (pP2)
+	AttrPVInverterPowerL2 uint = 205
+	// PV Inverter: Power L3 (pP3)
+	AttrPVInverterPowerL3 uint = 206
+	// GPS: Latitude (lat)
+	AttrGPSLatitude uint = 230
+	// GPS: Longitude (lon)
+	AttrGPSLongitude uint = 231
+	// GPS: Speed (sp)
+	AttrGPSSpeed uint = 232
+	// GPS: Course (cs)
+	AttrGPSCourse uint = 233
+	// GPS: Altitude (alt)
+	AttrGPSAltitude uint = 234
+	// System overview: PV - DC-coupled (Pdc)
+	AttrSystemOverviewPVDCCoupled uint = 442
+	// Motor Drive: Motor RPM (mr)
+	AttrMotorDriveMotorRPM uint = 800
+	// Motor Drive: Motor temperature (mt)
+	AttrMotorDriveMotorTemperature uint = 801
+	// Motor Drive: Motor power (mp)
+	AttrMotorDriveMotorPower uint = 802
+)
+
+// Codes of known data attributes
+const (
+	CodeGatewayGatewayID                      string = "g"
+	CodeBatteryMonitorVoltage                 string = "bv"
+	CodeBatteryMonitorCurrent                 string = "bc"
+	CodeBatteryMonitorConsumedAmphours        string = "ca"
+	CodeBatteryMonitorStateOfCharge           string = "bs"
+	CodeBatteryMonitorTimeToGo                string = "tg"
+	CodeBatteryMonitorDeepestDischarge        string = "H1"
+	CodeBatteryMonitorLastDischarge           string = "H2"
+	CodeBatteryMonitorAverageDischarge        string = "H3"
+	CodeBatteryMonitorChargeCycles            string = "H4"
+	CodeBatteryMonitorFullDischarges          string = "H5"
+	CodeBatteryMonitorTotalAhDrawn            string = "H6"
+	CodeBatteryMonitorMinimumVoltage          string = "H7"
+	CodeBatteryMonitorMaximumVoltage          string = "H8"
+	CodeBatteryMonitorTimeSinceLastFullCharge string = "H9"
+	CodeBatteryMonitorDischargedEnergy        string = "H17"
+	CodeBatteryMonitorChargedEnergy           string = "H18"
+	CodeSolarChargerBatteryVoltage            string = "ScV"
+	CodeSolarChargerBatteryCurrent            string = "ScI"
+	CodeSolarChargerChargeState               string = "ScS"
+	CodeSolarChargerPVVoltage                 string = "PVV"
+	CodeSolarChargerYieldToday                string = "YT"
+	CodeSolarChargerYieldYesterday            string = "YY"
+	CodeSolarChargerErrorCode                 string = "ScERR"
+	CodeSolarChargerPVPower                   string = "PVP"
+	CodeBatteryMonitorBatteryTemperature      string = "bT"
+	CodeBatteryMonitorMinimumCellVoltage      string = "mcV"
+	CodeBatteryMonitorMaximumCellVoltage      string = "McV"
+	CodeBatteryMonitorMinimumCellTemperature  string = "mcT"
+	CodeBatteryMonitorMaximumCellTemperature  string = "McT"
+	CodeBatteryMonitorAllowToCharge           string = "Bac"
+	CodeBatteryMonitorAllowToDischarge        string = "Bad"
+	CodePVInverterPowerL1                     string = "pP1"
+	CodePVInverterPowerL2                     string = "pP2"
+	CodePVInverterPowerL3                     string = "pP3"
+	CodeGPSLatitude                           string = "lat"
+	CodeGPSLongitude                          string = "lon"
+	CodeGPSSpeed                              string = "sp"
+	CodeGPSCourse                             string = "cs"
+	CodeGPSAltitude                           string = "alt"
+	CodeSystemOverviewPVDCCoupled             string = "Pdc"
+	CodeMotorDriveMotorRPM                    string = "mr"
+	CodeMotorDriveMotorTemperature            string = "mt"
+	CodeMotorDriveMotorPower                  string = "mp"
+)
+
+var dataAttributes = []DataAttribute{
+	{ID: 1, Code: "g", Description: "gatewayID", Unit: "", DeviceClass: "Gateway", ServiceType: "", EnumValues: []DataAttributeEnum{
+		{Value: 0, Name: "VGR, VGR2 or VER"},
+		{Value: 1, Name: "Venus"},
+	}},
+	{ID: 47, Code: "bv", Description: "Voltage", Unit: "V", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 49, Code: "bc", Description: "Current", Unit: "A", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 50, Code: "ca", Description: "Consumed Amphours", Unit: "Ah", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 51, Code: "bs", Description: "State of charge", Unit: "%", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 52, Code: "tg", Description: "Time to go", Unit: "h", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 58, Code: "H1", Description: "Deepest discharge", Unit: "Ah", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 59, Code: "H2", Description: "Last discharge", Unit: "Ah", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 60, Code: "H3", Description: "Average discharge", Unit: "Ah", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 61, Code: "H4", Description: "Charge cycles", Unit: "", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 62, Code: "H5", Description: "Full discharges", Unit: "", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 63, Code: "H6", Description: "Total Ah drawn", Unit: "Ah", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 64, Code: "H7", Description: "Minimum voltage", Unit: "V", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 65, Code: "H8", Description: "Maximum voltage", Unit: "V", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 66, Code: "H9", Description: "Time since last full charge", Unit: "s", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 69, Code: "H17", Description: "Discharged energy", Unit: "kWh", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 70, Code: "H18", Description: "Charged energy", Unit: "kWh", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 81, Code: "ScV", Description: "Battery voltage", Unit: "V", DeviceClass: "Solar Charger", ServiceType: "solarcharger"},
+	{ID: 82, Code: "ScI", Description: "Battery current", Unit: "A", DeviceClass: "Solar Charger", ServiceType: "solarcharger"},
+	{ID: 85, Code: "ScS", Description: "Charge state", Unit: "", DeviceClass: "Solar Charger", ServiceType: "solarcharger", EnumValues: []DataAttributeEnum{
+		{Value: 0, Name: "Off"},
+		{Value: 2, Name: "Fault"},
+		{Value: 3, Name: "Bulk"},
+		{Value: 4, Name: "Absorption"},
+		{Value: 5, Name: "Float"},
+		{Value: 6, Name: "Storage"},
+		{Value: 7, Name: "Equalize"},
+	}},
+	{ID: 86, Code: "PVV", Description: "PV voltage", Unit: "V", DeviceClass: "Solar Charger", ServiceType: "solarcharger"},
+	{ID: 94, Code: "YT", Description: "Yield today", Unit: "kWh", DeviceClass: "Solar Charger", ServiceType: "solarcharger"},
+	{ID: 96, Code: "YY", Description: "Yield yesterday", Unit: "kWh", DeviceClass: "Solar Charger", ServiceType: "solarcharger"},
+	{ID: 97, Code: "ScERR", Description: "Error code", Unit: "", DeviceClass: "Solar Charger", ServiceType: "solarcharger", EnumValues: []DataAttributeEnum{
+		{Value: 0, Name: "No error"},
+		{Value: 2, Name: "Battery voltage too high"},
+		{Value: 17, Name: "Charger temperature too high"},
+		{Value: 18, Name: "Charger over current"},
+		{Value: 20, Name: "Bulk time limit exceeded"},
+		{Value: 33, Name: "Input voltage too high (solar panel)"},
+		{Value: 34, Name: "Input current too high (solar panel)"},
+	}},
+	{ID: 107, Code: "PVP", Description: "PV power", Unit: "W", DeviceClass: "Solar Charger", ServiceType: "solarcharger"},
+	{ID: 115, Code: "bT", Description: "Battery temperature", Unit: "°C", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 173, Code: "mcV", Description: "Minimum cell voltage", Unit: "V", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 174, Code: "McV", Description: "Maximum cell voltage", Unit: "V", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 175, Code: "mcT", Description: "Minimum cell temperature", Unit: "°C", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 176, Code: "McT", Description: "Maximum cell temperature", Unit: "°C", DeviceClass: "Battery Monitor", ServiceType: "battery"},
+	{ID: 177, Code: "Bac", Description: "Allow to charge", Unit: "", DeviceClass: "Battery Monitor", ServiceType: "battery", EnumValues: []DataAttributeEnum{
+		{Value: 0, Name: "No"},
+		{Value: 1, Name: "Yes"},
+	}},
+	{ID: 178, Code: "Bad", Description: "Allow to discharge", Unit: "", DeviceClass: "Battery Monitor", ServiceType: "battery", EnumValues: []DataAttributeEnum{
+		{Value: 0, Name: "No"},
+		{Value: 1, Name: "Yes"},
+	}},
+	{ID: 204, Code: "pP1", Description: "Power L1", Unit: "W", DeviceClass: "PV Inverter", ServiceType: "pvinverter"},
+	{ID: 205, Code: "pP2", Description: "Power L2", Unit: "W", DeviceClass: "PV Inverter", ServiceType: "pvinverter"},
+	{ID: 206, Code: "pP3", Description: "Power L3", Unit: "W", DeviceClass: "PV Inverter", ServiceType: "pvinverter"},
+	{ID: 230, Code: "lat", Description: "Latitude", Unit: "", DeviceClass: "GPS", ServiceType: "gps"},
+	{ID: 231, Code: "lon", Description: "Longitude", Unit: "", DeviceClass: "GPS", ServiceType: "gps"},
+	{ID: 232, Code: "sp", Description: "Speed", Unit: "m/s", DeviceClass: "GPS", ServiceType: "gps"},
+	{ID: 233, Code: "cs", Description: "Course", Unit: "°", DeviceClass: "GPS", ServiceType: "gps"},
+	{ID: 234, Code: "alt", Description: "Altitude", Unit: "m", DeviceClass: "GPS", ServiceType: "gps"},
+	{ID: 442, Code: "Pdc", Description: "PV - DC-coupled", Unit: "W", DeviceClass: "System overview", ServiceType: "system"},
+	{ID: 800, Code: "mr", Description: "Motor RPM", Unit: "RPM", DeviceClass: "Motor Drive", ServiceType: "motordrive"},
+	{ID: 801, Code: "mt", Description: "Motor temperature", Unit: "°C", DeviceClass: "Motor Drive", ServiceType: "motordrive"},
+	{ID: 802, Code: "mp", Description: "Motor power", Unit: "W", DeviceClass: "Motor Drive", ServiceType: "motordrive"},
+}
@@ -0,0 +1,72 @@
+package vrm_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestDataAttributes(t *testing.T) {
+	voltage, ok := vrm.LookupDataAttribute(vrm.AttrBatteryMonitorVoltage)
+	if assert.True(t, ok) {
+		assert.Equal(t, vrm.CodeBatteryMonitorVoltage, voltage.Code)
+		assert.Equal(t, "V", voltage.Unit)
+	}
+
+	state, ok := vrm.LookupDataAttributeCode(vrm.CodeSolarChargerChargeState)
+	if assert.True(t, ok) {
+		name, ok := state.EnumName(3)
+		assert.True(t, ok)
+		assert.Equal(t, "Bulk", name)
+		name, ok = state.EnumName(5)
+		assert.True(t, ok)
+		assert.Equal(t, "Float", name)
+	}
+
+	gateway, ok := vrm.LookupDataAttribute(vrm.AttrGatewayGatewayID)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Gateway", gateway.DeviceClass)
+	}
+
+	_, ok = vrm.LookupDataAttributeCode("unknown")
+	assert.False(t, ok)
+
+	attrs := vrm.DataAttributes()
+	assert.NotEmpty(t, vrm.DataAttributesVersion)
+	for i := 1; i < len(attrs); i++ {
+		assert.True(t, attrs[i-1].ID < attrs[i].ID, "catalogue is ordered by ID")
+	}
+}
+
+func TestDataAttributesCoverFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob("vrmtest/testdata/installations/*/diagnostics.json")
+	assert.NoError(t, err)
+	seeds, err := filepath.Glob("cmd/vrm-attributes/testdata/*.json")
+	assert.NoError(t, err)
+	dumps := append(fixtures, seeds...)
+	if !assert.NotEmpty(t, fixtures) || !assert.NotEmpty(t, seeds) {
+		return
+	}
+
+	for _, dump := range dumps {
+		data, err := ioutil.ReadFile(dump)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		diag := vrm.DiagnosticsResponse{}
+		if !assert.NoError(t, json.Unmarshal(data, &diag)) {
+			continue
+		}
+		for _, r := range diag.Records {
+			attr, ok := vrm.LookupDataAttribute(r.DataAttributeID)
+			if assert.True(t, ok, "attribute %d of %s is missing, merge it with vrm-attributes -dump", r.DataAttributeID, dump) {
+				assert.Equal(t, r.Code, attr.Code)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package vrm
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Session is the set of VRM calls available on a logged in session as returned by Login. Code depending
+// on it can be tested against a fake, see package vrmtest.
+type Session interface {
+	// Session
+	Logout() error
+	LogoutContext(ctx context.Context) error
+	StoredToken() StoredToken
+	UserID() int
+
+	// Access tokens
+	ListAccessTokens() (*AccessTokensResponse, error)
+	ListAccessTokensContext(ctx context.Context) (*AccessTokensResponse, error)
+	CreateAccessToken(name string, expiry time.Time) (*CreateAccessTokenResponse, error)
+	CreateAccessTokenContext(ctx context.Context, name string, expiry time.Time) (*CreateAccessTokenResponse, error)
+	RevokeAccessToken(accessTokenID string) (*RevokeAccessTokenResponse, error)
+	RevokeAccessTokenContext(ctx context.Context, accessTokenID string) (*RevokeAccessTokenResponse, error)
+
+	// Users
+	Users() (*UsersResponse, error)
+	UsersContext(ctx context.Context) (*UsersResponse, error)
+	UsersWithQuery(query UsersQuery) (*UsersResponse, error)
+	UsersWithQueryContext(ctx context.Context, query UsersQuery) (*UsersResponse, error)
+	AllUsers(query UsersQuery) *UserIterator
+	AllUsersContext(ctx context.Context, query UsersQuery) *UserIterator
+
+	// Installations
+	Installations(userID int) (*InstallationsResponse, error)
+	InstallationsContext(ctx context.Context, userID int) (*InstallationsResponse, error)
+	AllInstallations(query InstallationsQuery) *InstallationIterator
+	AllInstallationsContext(ctx context.Context, query InstallationsQuery) *InstallationIterator
+	Installation(siteID int) (*Installation, error)
+	InstallationContext(ctx context.Context, siteID int) (*Installation, error)
+	InstallationSettings(siteID int) (*InstallationSettingsResponse, error)
+	InstallationSettingsContext(ctx context.Context, siteID int) (*InstallationSettingsResponse, error)
+	UpdateInstallationSettings(siteID int, settings InstallationSettings) (*InstallationSettingsResponse, error)
+	UpdateInstallationSettingsContext(ctx context.Context, siteID int, settings InstallationSettings) (*InstallationSettingsResponse, error)
+	SiteUsers(siteID int) (*SiteUsersResponse, error)
+	SiteUsersContext(ctx context.Context, siteID int) (*SiteUsersResponse, error)
+	InviteUser(siteID int, invite SiteInvite) (*SiteUserResponse, error)
+	InviteUserContext(ctx context.Context, siteID int, invite SiteInvite) (*SiteUserResponse, error)
+	SetUserAccessLevel(siteID int, userID int, accessLevel int) (*SiteUserResponse, error)
+	SetUserAccessLevelContext(ctx context.Context, siteID int, userID int, accessLevel int) (*SiteUserResponse, error)
+	RemoveUser(siteID int, userID int) (*SiteUserResponse, error)
+	RemoveUserContext(ctx context.Context, siteID int, userID int) (*SiteUserResponse, error)
+
+	// Tags
+	ListTags(siteID int) (*TagsResponse, error)
+	ListTagsContext(ctx context.Context, siteID int) (*TagsResponse, error)
+	AddTag(siteID int, tag string) (*TagResponse, error)
+	AddTagContext(ctx context.Context, siteID int, tag string) (*TagResponse, error)
+	RemoveTag(siteID int, tag string) (*TagResponse, error)
+	RemoveTagContext(ctx context.Context, siteID int, tag string) (*TagResponse, error)
+	InstallationsByTag(tag string) ([]Installation, error)
+	InstallationsByTagContext(ctx context.Context, tag string) ([]Installation, error)
+
+	// Data
+	SystemOverview(siteID int) (*SystemOverviewResponse, error)
+	SystemOverviewContext(ctx context.Context, siteID int) (*SystemOverviewResponse, error)
+	Diagnostics(siteID int, count uint16) (*DiagnosticsResponse, error)
+	DiagnosticsContext(ctx context.Context, siteID int, count uint16) (*DiagnosticsResponse, error)
+	Stats(siteID int) (*StatsResponse, error)
+	StatsContext(ctx context.Context, siteID int) (*StatsResponse, error)
+	StatsWithQuery(siteID int, query StatsQuery) (*StatsResponse, error)
+	StatsWithQueryContext(ctx context.Context, siteID int, query StatsQuery) (*StatsResponse, error)
+	DownloadData(siteID int) ([]byte, error)
+	DownloadDataContext(ctx context.Context, siteID int) ([]byte, error)
+	DownloadDataTo(w io.Writer, siteID int, query DownloadQuery) (int64, error)
+	DownloadDataToContext(ctx context.Context, w io.Writer, siteID int, query DownloadQuery) (int64, error)
+	GPSDownload(siteID int, start, end time.Time) (GPSTrack, error)
+	GPSDownloadContext(ctx context.Context, siteID int, start, end time.Time) (GPSTrack, error)
+
+	// Widgets
+	Widget(siteID int, name string, query WidgetQuery) (*WidgetResponse, error)
+	WidgetContext(ctx context.Context, siteID int, name string, query WidgetQuery) (*WidgetResponse, error)
+	BatterySummary(siteID int, instance int) (*BatterySummary, error)
+	BatterySummaryContext(ctx context.Context, siteID int, instance int) (*BatterySummary, error)
+	MPPTState(siteID int, instance int) (*DeviceState, error)
+	MPPTStateContext(ctx context.Context, siteID int, instance int) (*DeviceState, error)
+	VeBusState(siteID int, instance int) (*DeviceState, error)
+	VeBusStateContext(ctx context.Context, siteID int, instance int) (*DeviceState, error)
+	AlarmWidget(siteID int) (*AlarmWidgetResponse, error)
+	AlarmWidgetContext(ctx context.Context, siteID int) (*AlarmWidgetResponse, error)
+	HoursOfAC(siteID int, start, end time.Time) (*HoursOfACResponse, error)
+	HoursOfACContext(ctx context.Context, siteID int, start, end time.Time) (*HoursOfACResponse, error)
+	SolarChargerSummary(siteID int, instance int) (*SolarChargerSummary, error)
+	SolarChargerSummaryContext(ctx context.Context, siteID int, instance int) (*SolarChargerSummary, error)
+	BMSDiagnostics(siteID int, instance int) (*BMSSummary, error)
+	BMSDiagnosticsContext(ctx context.Context, siteID int, instance int) (*BMSSummary, error)
+	LithiumBMS(siteID int, instance int) (*BMSSummary, error)
+	LithiumBMSContext(ctx context.Context, siteID int, instance int) (*BMSSummary, error)
+	PVInverterStatus(siteID int, instance int) (*PVInverterStatus, error)
+	PVInverterStatusContext(ctx context.Context, siteID int, instance int) (*PVInverterStatus, error)
+	StatusWidget(siteID int) (*DeviceState, error)
+	StatusWidgetContext(ctx context.Context, siteID int) (*DeviceState, error)
+	MotorSummary(siteID int, instance int) (*MotorSummary, error)
+	MotorSummaryContext(ctx context.Context, siteID int, instance int) (*MotorSummary, error)
+	IOExtenderInOut(siteID int, instance int) (*IOExtenderInOut, error)
+	IOExtenderInOutContext(ctx context.Context, siteID int, instance int) (*IOExtenderInOut, error)
+	HistoricData(siteID int, instance int) (*HistoricData, error)
+	HistoricDataContext(ctx context.Context, siteID int, instance int) (*HistoricData, error)
+	GPS(siteID int) (*GPSPosition, error)
+	GPSContext(ctx context.Context, siteID int) (*GPSPosition, error)
+	Graph(siteID int, query WidgetQuery) (*GraphResponse, error)
+	GraphContext(ctx context.Context, siteID int, query WidgetQuery) (*GraphResponse, error)
+
+	// Alarms
+	AlarmRules(siteID int) (*AlarmRulesResponse, error)
+	AlarmRulesContext(ctx context.Context, siteID int) (*AlarmRulesResponse, error)
+	CreateAlarmRule(siteID int, rule AlarmRule) (*AlarmRuleResponse, error)
+	CreateAlarmRuleContext(ctx context.Context, siteID int, rule AlarmRule) (*AlarmRuleResponse, error)
+	UpdateAlarmRule(siteID int, rule AlarmRule) (*AlarmRuleResponse, error)
+	UpdateAlarmRuleContext(ctx context.Context, siteID int, rule AlarmRule) (*AlarmRuleResponse, error)
+	DeleteAlarmRule(siteID int, dataAttributeID int, instance int) (*AlarmRuleResponse, error)
+	DeleteAlarmRuleContext(ctx context.Context, siteID int, dataAttributeID int, instance int) (*AlarmRuleResponse, error)
+	Alarms(siteID int, query AlarmQuery) (*AlarmsResponse, error)
+	AlarmsContext(ctx context.Context, siteID int, query AlarmQuery) (*AlarmsResponse, error)
+}
+
+var _ Session = (*vrmSession)(nil)
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+
+	victron "github.com/christianschmizz/go-victron"
+)
+
+// Config declares the desired alarm rules. JSON files are accepted as well.
+//
+//	targets:
+//	  - tag: customer-x
+//	    prune: true
+//	    rules:
+//	      - attribute: 51
+//	        instance: 288
+//	        enabled: true
+//	        notifyAfter: 60
+//	        low: 20
+//	        lowHysteresis: 5
+type Config struct {
+	Targets []Target `yaml:"targets" json:"targets"`
+}
+
+// Target selects installations by site ID or tag and the rules they should have.
+type Target struct {
+	Site int    `yaml:"site" json:"site"`
+	Tag  string `yaml:"tag" json:"tag"`
+	// Remove rules from VRM which are not declared
+	Prune bool   `yaml:"prune" json:"prune"`
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+type Rule struct {
+	Attribute      int      `yaml:"attribute" json:"attribute"`
+	Instance       int      `yaml:"instance" json:"instance"`
+	Enabled        *bool    `yaml:"enabled" json:"enabled"`
+	NotifyAfter    int      `yaml:"notifyAfter" json:"notifyAfter"`
+	Low            *float64 `yaml:"low" json:"low"`
+	LowHysteresis  float64  `yaml:"lowHysteresis" json:"lowHysteresis"`
+	High           *float64 `yaml:"high" json:"high"`
+	HighHysteresis float64  `yaml:"highHysteresis" json:"highHysteresis"`
+}
+
+func (r Rule) alarmRule() victron.AlarmRule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return victron.AlarmRule{
+		DataAttributeID:     r.Attribute,
+		Instance:            r.Instance,
+		Enabled:             enabled,
+		NotifyAfterSeconds:  r.NotifyAfter,
+		LowAlarm:            r.Low,
+		LowAlarmHysteresis:  r.LowHysteresis,
+		HighAlarm:           r.High,
+		HighAlarmHysteresis: r.HighHysteresis,
+	}
+}
+
+func loadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Config{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", filename, err)
+	}
+
+	for i, target := range config.Targets {
+		if (target.Site == 0) == (len(target.Tag) == 0) {
+			return nil, fmt.Errorf("target %d must have either a site or a tag", i+1)
+		}
+	}
+	return &config, nil
+}
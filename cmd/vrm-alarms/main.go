@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	victron "github.com/christianschmizz/go-victron"
+)
+
+func main() {
+	username := flag.String("username", "", "VRM username")
+	password := flag.String("password", "", "VRM password")
+	token := flag.String("token", "", "VRM personal access token, instead of username and password")
+	configFile := flag.String("config", "alarms.yaml", "YAML or JSON file of desired alarm rules")
+	yes := flag.Bool("yes", false, "Apply the plan without asking for confirmation")
+	flag.Parse()
+
+	if *token == "" && (*username == "" || *password == "") {
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load config")
+	}
+
+	opts := []victron.Option{
+		victron.WithRetryPolicy(victron.DefaultRetryPolicy),
+		victron.WithRateLimiter(victron.NewTokenBucket(2, 1)),
+	}
+	var session victron.Session
+	if *token != "" {
+		session, err = victron.NewSessionWithAccessToken(0, *token, opts...)
+	} else {
+		session, err = victron.Login(*username, *password, opts...)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("login failed")
+	}
+
+	// Resolve targets to sites; rules of later targets override earlier ones for the same attribute
+	sites := map[int]string{}
+	desired := map[int]map[ruleKey]victron.AlarmRule{}
+	prune := map[int]bool{}
+	for _, target := range config.Targets {
+		var installs []victron.Installation
+		if target.Tag != "" {
+			installs, err = session.InstallationsByTag(target.Tag)
+		} else {
+			var install *victron.Installation
+			if install, err = session.Installation(target.Site); err == nil {
+				installs = append(installs, *install)
+			}
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to resolve installations")
+		}
+		if len(installs) == 0 {
+			log.Warn().Str("tag", target.Tag).Msg("no installations found")
+		}
+
+		for _, install := range installs {
+			sites[install.SiteID] = install.Name
+			if desired[install.SiteID] == nil {
+				desired[install.SiteID] = map[ruleKey]victron.AlarmRule{}
+			}
+			for _, rule := range target.Rules {
+				r := rule.alarmRule()
+				desired[install.SiteID][keyOf(r)] = r
+			}
+			prune[install.SiteID] = prune[install.SiteID] || target.Prune
+		}
+	}
+
+	siteIDs := make([]int, 0, len(sites))
+	for siteID := range sites {
+		siteIDs = append(siteIDs, siteID)
+	}
+	sort.Ints(siteIDs)
+
+	var changes []change
+	for _, siteID := range siteIDs {
+		current, err := session.AlarmRules(siteID)
+		if err != nil {
+			log.Fatal().Err(err).Int("site", siteID).Msg("failed to retrieve alarm rules")
+		}
+
+		rules := make([]victron.AlarmRule, 0, len(desired[siteID]))
+		for _, r := range desired[siteID] {
+			rules = append(rules, r)
+		}
+		changes = append(changes, diff(siteID, current.Rules, rules, prune[siteID])...)
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Alarm rules are up to date.")
+		return
+	}
+
+	printPlan(os.Stdout, sites, changes)
+
+	if !*yes {
+		fmt.Printf("\nApply %d changes? [y/N] ", len(changes))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Println("Aborted.")
+			return
+		}
+	}
+
+	for _, c := range changes {
+		switch c.action {
+		case create:
+			_, err = session.CreateAlarmRule(c.siteID, c.rule)
+		case update:
+			_, err = session.UpdateAlarmRule(c.siteID, c.rule)
+		case remove:
+			_, err = session.DeleteAlarmRule(c.siteID, c.rule.DataAttributeID, c.rule.Instance)
+		}
+		if err != nil {
+			log.Fatal().Err(err).Int("site", c.siteID).Int("attribute", c.rule.DataAttributeID).Msg("failed to apply change")
+		}
+	}
+	fmt.Printf("Applied %d changes.\n", len(changes))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	victron "github.com/christianschmizz/go-victron"
+)
+
+type action int
+
+const (
+	create action = iota
+	update
+	remove
+)
+
+func (a action) String() string {
+	return [...]string{"+", "~", "-"}[a]
+}
+
+type change struct {
+	action action
+	siteID int
+	// Desired rule for create/update, current rule for remove
+	rule victron.AlarmRule
+}
+
+type ruleKey struct {
+	attribute int
+	instance  int
+}
+
+func keyOf(r victron.AlarmRule) ruleKey {
+	return ruleKey{r.DataAttributeID, r.Instance}
+}
+
+// diff returns the changes turning the current rules of a site into the desired ones.
+func diff(siteID int, current, desired []victron.AlarmRule, prune bool) []change {
+	existing := map[ruleKey]victron.AlarmRule{}
+	for _, r := range current {
+		existing[keyOf(r)] = r
+	}
+
+	var changes []change
+	wanted := map[ruleKey]bool{}
+	for _, r := range desired {
+		wanted[keyOf(r)] = true
+		cur, ok := existing[keyOf(r)]
+		switch {
+		case !ok:
+			changes = append(changes, change{create, siteID, r})
+		case !equalRules(cur, r):
+			changes = append(changes, change{update, siteID, r})
+		}
+	}
+
+	if prune {
+		for _, r := range current {
+			if !wanted[keyOf(r)] {
+				changes = append(changes, change{remove, siteID, r})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := keyOf(changes[i].rule), keyOf(changes[j].rule)
+		if a.attribute != b.attribute {
+			return a.attribute < b.attribute
+		}
+		return a.instance < b.instance
+	})
+	return changes
+}
+
+func equalRules(a, b victron.AlarmRule) bool {
+	return a.Enabled == b.Enabled &&
+		a.NotifyAfterSeconds == b.NotifyAfterSeconds &&
+		equalThreshold(a.LowAlarm, b.LowAlarm) &&
+		a.LowAlarmHysteresis == b.LowAlarmHysteresis &&
+		equalThreshold(a.HighAlarm, b.HighAlarm) &&
+		a.HighAlarmHysteresis == b.HighAlarmHysteresis
+}
+
+func equalThreshold(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatThreshold(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func printPlan(w io.Writer, sites map[int]string, changes []change) {
+	lastSite := 0
+	for _, c := range changes {
+		if c.siteID != lastSite {
+			fmt.Fprintf(w, "Site: %s (ID: %d)\n", sites[c.siteID], c.siteID)
+			lastSite = c.siteID
+		}
+		r := c.rule
+		fmt.Fprintf(w, "\t%s attribute %d instance %d: enabled=%t notifyAfter=%ds low=%s/%g high=%s/%g\n",
+			c.action, r.DataAttributeID, r.Instance, r.Enabled, r.NotifyAfterSeconds,
+			formatThreshold(r.LowAlarm), r.LowAlarmHysteresis, formatThreshold(r.HighAlarm), r.HighAlarmHysteresis)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	victron "github.com/christianschmizz/go-victron"
+)
+
+func threshold(v float64) *float64 {
+	return &v
+}
+
+func TestDiff(t *testing.T) {
+	soc := victron.AlarmRule{DataAttributeID: 51, Instance: 288, Enabled: true, NotifyAfterSeconds: 60, LowAlarm: threshold(20), LowAlarmHysteresis: 5}
+	voltage := victron.AlarmRule{DataAttributeID: 47, Instance: 288, Enabled: true, LowAlarm: threshold(11.5)}
+	temperature := victron.AlarmRule{DataAttributeID: 115, Instance: 288, Enabled: true, HighAlarm: threshold(45)}
+
+	t.Run("no-op", func(t *testing.T) {
+		same := soc
+		same.LowAlarm = threshold(20)
+		assert.Empty(t, diff(1234, []victron.AlarmRule{soc, voltage}, []victron.AlarmRule{same, voltage}, true))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		changes := diff(1234, []victron.AlarmRule{soc}, []victron.AlarmRule{soc, temperature, voltage}, false)
+		assert.Equal(t, []change{{create, 1234, voltage}, {create, 1234, temperature}}, changes)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		raised := soc
+		raised.LowAlarm = threshold(30)
+		disabled := voltage
+		disabled.Enabled = false
+		changes := diff(1234, []victron.AlarmRule{soc, voltage}, []victron.AlarmRule{raised, disabled}, false)
+		assert.Equal(t, []change{{update, 1234, disabled}, {update, 1234, raised}}, changes)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		current := []victron.AlarmRule{soc, voltage}
+		assert.Equal(t, []change{{remove, 1234, voltage}}, diff(1234, current, []victron.AlarmRule{soc}, true))
+		assert.Empty(t, diff(1234, current, []victron.AlarmRule{soc}, false), "rules are only removed when pruning")
+	})
+
+	t.Run("instances", func(t *testing.T) {
+		other := soc
+		other.Instance = 289
+		assert.Equal(t, []change{{create, 1234, other}}, diff(1234, []victron.AlarmRule{soc}, []victron.AlarmRule{soc, other}, false))
+	})
+}
+
+func TestEqualRules(t *testing.T) {
+	rule := victron.AlarmRule{DataAttributeID: 51, Enabled: true, NotifyAfterSeconds: 60, LowAlarm: threshold(20), LowAlarmHysteresis: 5}
+	assert.True(t, equalRules(rule, rule))
+
+	for name, modify := range map[string]func(r *victron.AlarmRule){
+		"enabled":        func(r *victron.AlarmRule) { r.Enabled = false },
+		"notifyAfter":    func(r *victron.AlarmRule) { r.NotifyAfterSeconds = 120 },
+		"low":            func(r *victron.AlarmRule) { r.LowAlarm = threshold(25) },
+		"low removed":    func(r *victron.AlarmRule) { r.LowAlarm = nil },
+		"lowHysteresis":  func(r *victron.AlarmRule) { r.LowAlarmHysteresis = 2 },
+		"high":           func(r *victron.AlarmRule) { r.HighAlarm = threshold(90) },
+		"highHysteresis": func(r *victron.AlarmRule) { r.HighAlarmHysteresis = 2 },
+	} {
+		other := rule
+		modify(&other)
+		assert.False(t, equalRules(rule, other), name)
+		assert.False(t, equalRules(other, rule), name)
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "vrm-alarms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	filename := filepath.Join(dir, "alarms.yaml")
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadConfig(t *testing.T) {
+	config, err := loadConfig(writeConfig(t, `
+targets:
+  - tag: customer-x
+    prune: true
+    rules:
+      - attribute: 51
+        instance: 288
+        notifyAfter: 60
+        low: 20
+        lowHysteresis: 5
+  - site: 1234
+    rules:
+      - attribute: 47
+        enabled: false
+`))
+	if assert.NoError(t, err) && assert.Len(t, config.Targets, 2) {
+		assert.Equal(t, victron.AlarmRule{
+			DataAttributeID:    51,
+			Instance:           288,
+			Enabled:            true,
+			NotifyAfterSeconds: 60,
+			LowAlarm:           threshold(20),
+			LowAlarmHysteresis: 5,
+		}, config.Targets[0].Rules[0].alarmRule())
+		assert.True(t, config.Targets[0].Prune)
+		assert.False(t, config.Targets[1].Rules[0].alarmRule().Enabled)
+	}
+
+	for name, content := range map[string]string{
+		"no site or tag":    "targets:\n  - rules: []\n",
+		"both site and tag": "targets:\n  - site: 1234\n    tag: customer-x\n",
+		"invalid yaml":      "targets: [\n",
+		"invalid type":      "targets:\n  - site: boat\n",
+	} {
+		_, err := loadConfig(writeConfig(t, content))
+		assert.Error(t, err, name)
+	}
+
+	_, err = loadConfig(filepath.Join(os.TempDir(), "does-not-exist.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
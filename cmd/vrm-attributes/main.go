@@ -0,0 +1,319 @@
+// Command vrm-attributes maintains the catalogue of VRM data attributes. It merges diagnostics of
+// live installations or of dumped diagnostics responses into attributes.json and generates the
+// Go catalogue from it.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	victron "github.com/christianschmizz/go-victron"
+)
+
+type catalogue struct {
+	Version    string                  `json:"version"`
+	Attributes []victron.DataAttribute `json:"attributes"`
+}
+
+func main() {
+	cataloguePath := flag.String("catalogue", "attributes.json", "Catalogue of data attributes to refresh")
+	out := flag.String("out", "attributes_gen.go", "Generated Go file")
+	dumps := flag.String("dump", "", "Comma separated files of dumped diagnostics responses to merge")
+	username := flag.String("username", "", "VRM username for merging live diagnostics")
+	password := flag.String("password", "", "VRM password")
+	token := flag.String("token", "", "VRM personal access token, instead of username and password")
+	sites := flag.String("sites", "", "Comma separated site IDs to read diagnostics from, defaults to all installations")
+	version := flag.String("version", time.Now().Format("2006-01-02"), "Version of the catalogue if it changes")
+	flag.Parse()
+
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+
+	cat := catalogue{}
+	data, err := ioutil.ReadFile(*cataloguePath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read catalogue")
+	}
+	if err := json.Unmarshal(data, &cat); err != nil {
+		log.Fatal().Err(err).Msg("failed to parse catalogue")
+	}
+
+	var diagnostics []*victron.DiagnosticsResponse
+	for _, dump := range strings.Split(*dumps, ",") {
+		if len(dump) == 0 {
+			continue
+		}
+		diag, err := readDump(dump)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", dump).Msg("failed to read dump")
+		}
+		diagnostics = append(diagnostics, diag)
+	}
+	if *token != "" || *username != "" {
+		live, err := fetchDiagnostics(*username, *password, *token, *sites)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to fetch diagnostics")
+		}
+		diagnostics = append(diagnostics, live...)
+	}
+
+	changed := false
+	for _, diag := range diagnostics {
+		changed = merge(&cat, diag) || changed
+	}
+	if changed {
+		cat.Version = *version
+		data, err := json.MarshalIndent(cat, "", "  ")
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to encode catalogue")
+		}
+		if err := ioutil.WriteFile(*cataloguePath, append(data, '\n'), 0644); err != nil {
+			log.Fatal().Err(err).Msg("failed to write catalogue")
+		}
+		log.Info().Str("version", cat.Version).Int("attributes", len(cat.Attributes)).Msg("catalogue updated")
+	}
+
+	src, err := generate(cat, *cataloguePath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to generate catalogue")
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal().Err(err).Msg("failed to write generated catalogue")
+	}
+}
+
+func readDump(filename string) (*victron.DiagnosticsResponse, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	diag := victron.DiagnosticsResponse{}
+	if err := json.Unmarshal(data, &diag); err != nil {
+		return nil, err
+	}
+	return &diag, nil
+}
+
+func fetchDiagnostics(username, password, token, sites string) ([]*victron.DiagnosticsResponse, error) {
+	opts := []victron.Option{
+		victron.WithRetryPolicy(victron.DefaultRetryPolicy),
+		victron.WithRateLimiter(victron.NewTokenBucket(2, 1)),
+	}
+	var (
+		session victron.Session
+		err     error
+	)
+	if token != "" {
+		session, err = victron.NewSessionWithAccessToken(0, token, opts...)
+	} else {
+		session, err = victron.Login(username, password, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var siteIDs []int
+	for _, site := range strings.Split(sites, ",") {
+		if len(site) == 0 {
+			continue
+		}
+		siteID, err := strconv.Atoi(site)
+		if err != nil {
+			return nil, err
+		}
+		siteIDs = append(siteIDs, siteID)
+	}
+	if len(siteIDs) == 0 {
+		installs, err := session.Installations(session.UserID())
+		if err != nil {
+			return nil, err
+		}
+		for _, install := range installs.Records {
+			siteIDs = append(siteIDs, install.SiteID)
+		}
+	}
+
+	var diagnostics []*victron.DiagnosticsResponse
+	for _, siteID := range siteIDs {
+		diag, err := session.Diagnostics(siteID, 1000)
+		if err != nil {
+			return nil, err
+		}
+		log.Debug().Int("site", siteID).Int("records", len(diag.Records)).Msg("fetched diagnostics")
+		diagnostics = append(diagnostics, diag)
+	}
+	return diagnostics, nil
+}
+
+// merge adds new attributes of the diagnostics to the catalogue and completes existing ones.
+func merge(cat *catalogue, diag *victron.DiagnosticsResponse) bool {
+	index := map[uint]int{}
+	for i, a := range cat.Attributes {
+		index[a.ID] = i
+	}
+
+	changed := false
+	for _, r := range diag.Records {
+		attr := victron.DataAttribute{
+			ID:          r.DataAttributeID,
+			Code:        r.Code,
+			Description: r.Description,
+			Unit:        r.Unit(),
+			DeviceClass: r.Device,
+			ServiceType: r.DBusServiceTypeName(),
+		}
+		for _, e := range r.DataAttributeEnumValues {
+			var value int
+			if err := json.Unmarshal(e.Value, &value); err == nil {
+				attr.EnumValues = append(attr.EnumValues, victron.DataAttributeEnum{Value: value, Name: e.Name})
+			}
+		}
+
+		i, ok := index[attr.ID]
+		if !ok {
+			index[attr.ID] = len(cat.Attributes)
+			cat.Attributes = append(cat.Attributes, attr)
+			changed = true
+			continue
+		}
+
+		// Keep known values which are missing in the diagnostics
+		existing := &cat.Attributes[i]
+		before, _ := json.Marshal(existing)
+		if len(attr.Code) > 0 {
+			existing.Code = attr.Code
+		}
+		if len(attr.Description) > 0 {
+			existing.Description = attr.Description
+		}
+		if len(attr.Unit) > 0 {
+			existing.Unit = attr.Unit
+		}
+		if len(attr.DeviceClass) > 0 {
+			existing.DeviceClass = attr.DeviceClass
+		}
+		if len(attr.ServiceType) > 0 {
+			existing.ServiceType = attr.ServiceType
+		}
+		existing.EnumValues = mergeEnums(existing.EnumValues, attr.EnumValues)
+		after, _ := json.Marshal(existing)
+		changed = changed || !bytes.Equal(before, after)
+	}
+
+	sort.Slice(cat.Attributes, func(i, j int) bool { return cat.Attributes[i].ID < cat.Attributes[j].ID })
+	return changed
+}
+
+// mergeEnums adds the enum values of the diagnostics to the known ones. Diagnostics often list only
+// some values of an enum, so known values are never dropped.
+func mergeEnums(known, values []victron.DataAttributeEnum) []victron.DataAttributeEnum {
+	merged := append([]victron.DataAttributeEnum(nil), known...)
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Value < merged[j].Value })
+	for _, v := range values {
+		i := sort.Search(len(merged), func(i int) bool { return merged[i].Value >= v.Value })
+		if i < len(merged) && merged[i].Value == v.Value {
+			merged[i].Name = v.Name
+			continue
+		}
+		merged = append(merged, victron.DataAttributeEnum{})
+		copy(merged[i+1:], merged[i:])
+		merged[i] = v
+	}
+	return merged
+}
+
+// identifier turns a description like "PV - DC-coupled" into "PVDCCoupled".
+func identifier(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			runes := []rune(word)
+			if runes[0] > unicode.MaxASCII {
+				continue
+			}
+			runes[0] = unicode.ToUpper(runes[0])
+			b.WriteString(string(runes))
+		}
+	}
+	name := b.String()
+	if len(name) > 0 && unicode.IsDigit(rune(name[0])) {
+		name = "N" + name
+	}
+	return name
+}
+
+type constant struct {
+	Name      string
+	Attribute victron.DataAttribute
+}
+
+var sourceTemplate = template.Must(template.New("").Parse(`// Code generated by vrm-attributes from {{ .Source }}; DO NOT EDIT.
+
+package vrm
+
+// Version of the data attribute catalogue
+const DataAttributesVersion = {{ printf "%q" .Version }}
+
+// IDs of known data attributes
+const (
+{{- range .Constants }}
+	// {{ .Attribute.DeviceClass }}: {{ .Attribute.Description }}{{ if .Attribute.Code }} ({{ .Attribute.Code }}){{ end }}
+	Attr{{ .Name }} uint = {{ .Attribute.ID }}
+{{- end }}
+)
+
+// Codes of known data attributes
+const (
+{{- range .Constants }}{{ if .Attribute.Code }}
+	Code{{ .Name }} string = {{ printf "%q" .Attribute.Code }}
+{{- end }}{{ end }}
+)
+
+var dataAttributes = []DataAttribute{
+{{- range .Constants }}
+	{ID: {{ .Attribute.ID }}, Code: {{ printf "%q" .Attribute.Code }}, Description: {{ printf "%q" .Attribute.Description }}, Unit: {{ printf "%q" .Attribute.Unit }}, DeviceClass: {{ printf "%q" .Attribute.DeviceClass }}, ServiceType: {{ printf "%q" .Attribute.ServiceType }}
+	{{- if .Attribute.EnumValues }}, EnumValues: []DataAttributeEnum{
+	{{- range .Attribute.EnumValues }}
+		{Value: {{ .Value }}, Name: {{ printf "%q" .Name }}},
+	{{- end }}
+	}{{ end }}},
+{{- end }}
+}
+`))
+
+func generate(cat catalogue, source string) ([]byte, error) {
+	var constants []constant
+	used := map[string]bool{}
+	for _, a := range cat.Attributes {
+		name := identifier(a.DeviceClass, a.Description)
+		if len(name) == 0 || used[name] {
+			name += strconv.Itoa(int(a.ID))
+		}
+		used[name] = true
+		constants = append(constants, constant{Name: name, Attribute: a})
+	}
+
+	var buf bytes.Buffer
+	if err := sourceTemplate.Execute(&buf, struct {
+		Source    string
+		Version   string
+		Constants []constant
+	}{source, cat.Version, constants}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -15,6 +15,8 @@ import (
 func main() {
 	username := flag.String("username", "", "VRM username")
 	password := flag.String("password", "", "VRM password")
+	rate := flag.Float64("rate", 2, "Maximum number of requests per second")
+	logRequests := flag.Bool("log-requests", false, "Log every request to the VRM API")
 	flag.Parse()
 
 	if *username == "" || *password == "" {
@@ -24,16 +26,18 @@ func main() {
 
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
 
-	session, err := victron.Login(*username, *password)
+	opts := []victron.Option{victron.WithRateLimiter(victron.NewTokenBucket(*rate, 1))}
+	if *logRequests {
+		opts = append(opts, victron.WithLogger(log.Logger))
+	}
+	session, err := victron.Login(*username, *password, opts...)
 	if err != nil {
 		log.Fatal().Err(err).Msg("login failed")
 	}
 
-	installs, err := session.Installations(session.UserID)
-	if err != nil {
-		log.Fatal().Err(err).Msg("")
-	}
-	for _, site := range installs.Records {
+	it := session.AllInstallations(victron.InstallationsQuery{})
+	for it.Next() {
+		site := it.Installation()
 		fmt.Printf("Site: %s (ID: %d)\n", site.Name, site.SiteID)
 
 		{
@@ -57,4 +61,7 @@ func main() {
 			}
 		}
 	}
+	if err := it.Err(); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
 }
@@ -0,0 +1,191 @@
+package vrm
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of the timestamp column tried in order, besides Unix timestamps
+var csvTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+	"02/01/2006 15:04:05",
+}
+
+// CSVRow is a single row of a data export. Values are keyed by column header as returned by
+// CSVExportReader.Header.
+type CSVRow struct {
+	Time   time.Time
+	Values map[string]string
+}
+
+// Float returns the value of the given column as number. ok is false if the column is missing or empty.
+func (r CSVRow) Float(column string) (value float64, ok bool) {
+	v, found := r.Values[column]
+	if !found || len(v) == 0 {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// CSVExportReader reads the rows of a CSV data export as created by DownloadDataTo with DownloadFormatCSV.
+// The first column holds the timestamp. Header lines preceding the first row with a timestamp are merged
+// into one header per column, e.g. device, description and unit.
+type CSVExportReader struct {
+	r        *csv.Reader
+	header   []string
+	location *time.Location
+	// Row read while scanning the header
+	pending []string
+}
+
+// NewCSVExportReader creates a reader interpreting timestamps without zone in loc, or UTC if loc is nil.
+func NewCSVExportReader(r io.Reader, loc *time.Location) *CSVExportReader {
+	if loc == nil {
+		loc = time.UTC
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return &CSVExportReader{r: cr, location: loc}
+}
+
+// Header returns the merged column headers, reading them if necessary.
+func (e *CSVExportReader) Header() ([]string, error) {
+	if e.header != nil {
+		return e.header, nil
+	}
+
+	var headers [][]string
+	for {
+		record, err := e.r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(headers) > 0 && len(record) > 0 {
+			if _, err := e.parseTime(record[0]); err == nil {
+				e.pending = record
+				break
+			}
+		}
+		headers = append(headers, record)
+	}
+	if len(headers) == 0 {
+		return nil, errors.New("csv export has no header")
+	}
+
+	e.header = mergeHeaders(headers)
+	return e.header, nil
+}
+
+// Read returns the next row or io.EOF at the end of the export.
+func (e *CSVExportReader) Read() (*CSVRow, error) {
+	header, err := e.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	record := e.pending
+	e.pending = nil
+	if record == nil {
+		if record, err = e.r.Read(); err != nil {
+			return nil, err
+		}
+	}
+	if len(record) == 0 {
+		return nil, errors.New("empty csv record")
+	}
+
+	t, err := e.parseTime(record[0])
+	if err != nil {
+		return nil, err
+	}
+
+	row := &CSVRow{Time: t, Values: make(map[string]string, len(record)-1)}
+	for i := 1; i < len(record) && i < len(header); i++ {
+		row.Values[header[i]] = record[i]
+	}
+	return row, nil
+}
+
+func (e *CSVExportReader) parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// Distinguish seconds from milliseconds
+		if ts > 1e11 {
+			return time.Unix(0, ts*int64(time.Millisecond)), nil
+		}
+		return time.Unix(ts, 0), nil
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, e.location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// mergeHeaders joins the non-empty cells of multiple header lines per column. Columns of several devices
+// of the same type get the same header; repeated headers are numbered, e.g. "Battery Monitor Voltage V (2)".
+func mergeHeaders(headers [][]string) []string {
+	columns := 0
+	for _, h := range headers {
+		if len(h) > columns {
+			columns = len(h)
+		}
+	}
+
+	merged := make([]string, columns)
+	for i := range merged {
+		var parts []string
+		for _, h := range headers {
+			if i < len(h) && len(strings.TrimSpace(h[i])) > 0 {
+				parts = append(parts, strings.TrimSpace(h[i]))
+			}
+		}
+		merged[i] = strings.Join(parts, " ")
+	}
+
+	seen := make(map[string]int, columns)
+	for i, header := range merged {
+		seen[header]++
+		if n := seen[header]; n > 1 {
+			merged[i] = fmt.Sprintf("%s (%d)", header, n)
+		}
+	}
+	return merged
+}
+
+// ParseCSVExport reads a whole CSV data export into memory.
+func ParseCSVExport(r io.Reader, loc *time.Location) ([]string, []CSVRow, error) {
+	reader := NewCSVExportReader(r, loc)
+	header, err := reader.Header()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []CSVRow
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return header, rows, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, *row)
+	}
+}
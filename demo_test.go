@@ -0,0 +1,30 @@
+package vrm_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// demoLogin answers the demo login as user 22 and reports whether r was one.
+func demoLogin(w http.ResponseWriter, r *http.Request) bool {
+	if !strings.HasSuffix(r.URL.Path, "/auth/loginAsDemo") {
+		return false
+	}
+	fmt.Fprint(w, `{"token": "demo-token", "idUser": "22"}`)
+	return true
+}
+
+// newDemoServer starts a server answering the demo login and passing all other requests to handler.
+// It is closed when the test finishes.
+func newDemoServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !demoLogin(w, r) {
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
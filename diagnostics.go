@@ -0,0 +1,155 @@
+package vrm
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type DiagnosticsEnumValue struct {
+	Name  string          `json:"nameEnum"`
+	Value json.RawMessage `json:"valueEnum"`
+}
+
+type DiagnosticsRecord struct {
+	ID                      uint                   `json:"id"`
+	Device                  string                 `json:"Device"`
+	Instance                uint                   `json:"instance"`
+	Code                    string                 `json:"code"`
+	Description             string                 `json:"description"`
+	SiteID                  uint                   `json:"idSite"`
+	Timestamp               uint                   `json:"timestamp"`
+	FormatWithUnit          string                 `json:"formatWithUnit"`
+	DBusServiceType         json.RawMessage        `json:"dbusServiceType"`
+	DBusPath                json.RawMessage        `json:"dbusPath"`
+	RawValue                json.RawMessage        `json:"rawValue"`
+	FormattedValue          json.RawMessage        `json:"formattedValue"`
+	DataAttributeID         uint                   `json:"idDataAttribute"`
+	DataAttributeEnumValues []DiagnosticsEnumValue `json:"dataAttributeEnumValues"`
+}
+
+// rawString returns a JSON string or number as text. null yields an empty string.
+func rawString(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// rawFloat returns a JSON number or numeric string as float.
+func rawFloat(raw json.RawMessage) (float64, bool) {
+	s := rawString(raw)
+	if len(s) == 0 {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// Value returns the numeric value of the record. Records without rawValue fall back to the formatted value.
+func (r *DiagnosticsRecord) Value() (float64, bool) {
+	if f, ok := rawFloat(r.RawValue); ok {
+		return f, true
+	}
+	return rawFloat(r.FormattedValue)
+}
+
+// Text returns the formatted value as string.
+func (r *DiagnosticsRecord) Text() string {
+	return rawString(r.FormattedValue)
+}
+
+// EnumName resolves the value of an enum attribute to its name.
+func (r *DiagnosticsRecord) EnumName() (string, bool) {
+	if len(r.DataAttributeEnumValues) == 0 {
+		return "", false
+	}
+	value, ok := r.Value()
+	if !ok {
+		// Some attributes already report the name as formatted value
+		text := r.Text()
+		for _, e := range r.DataAttributeEnumValues {
+			if e.Name == text {
+				return e.Name, true
+			}
+		}
+		return "", false
+	}
+	for _, e := range r.DataAttributeEnumValues {
+		if v, ok := rawFloat(e.Value); ok && v == value {
+			return e.Name, true
+		}
+	}
+	return "", false
+}
+
+// Matches printf verbs as used in formatWithUnit, e.g. %s, %.2F or %d
+var formatVerbRegexp = regexp.MustCompile(`%[-+ #0]*[0-9]*(\.[0-9]+)?[a-zA-Z]`)
+
+// Unit returns the unit part of FormatWithUnit, e.g. "V" for "%.2F V".
+func (r *DiagnosticsRecord) Unit() string {
+	return parseUnit(r.FormatWithUnit)
+}
+
+func parseUnit(format string) string {
+	format = strings.Replace(format, "%%", "\x00", -1)
+	format = formatVerbRegexp.ReplaceAllString(format, "")
+	return strings.TrimSpace(strings.Replace(format, "\x00", "%", -1))
+}
+
+// DBusServiceTypeName returns the D-Bus service type, e.g. "battery", or an empty string.
+func (r *DiagnosticsRecord) DBusServiceTypeName() string {
+	return rawString(r.DBusServiceType)
+}
+
+// DBusPathName returns the D-Bus path, e.g. "/Dc/0/Voltage", or an empty string.
+func (r *DiagnosticsRecord) DBusPathName() string {
+	return rawString(r.DBusPath)
+}
+
+type diagnosticsKey struct {
+	device   string
+	instance uint
+	code     string
+	id       uint
+}
+
+// DiagnosticsIndex looks up diagnostics records by device and instance.
+type DiagnosticsIndex struct {
+	records map[diagnosticsKey]*DiagnosticsRecord
+}
+
+// Index builds a lookup index of the response's records.
+func (d *DiagnosticsResponse) Index() *DiagnosticsIndex {
+	idx := &DiagnosticsIndex{records: make(map[diagnosticsKey]*DiagnosticsRecord, len(d.Records)*2)}
+	for i := range d.Records {
+		r := &d.Records[i]
+		idx.records[diagnosticsKey{device: r.Device, instance: r.Instance, id: r.DataAttributeID}] = r
+		if len(r.Code) > 0 {
+			idx.records[diagnosticsKey{device: r.Device, instance: r.Instance, code: r.Code}] = r
+		}
+	}
+	return idx
+}
+
+// ByCode returns the record of a device instance with the given attribute code, e.g. "bv".
+func (idx *DiagnosticsIndex) ByCode(device string, instance uint, code string) (*DiagnosticsRecord, bool) {
+	r, ok := idx.records[diagnosticsKey{device: device, instance: instance, code: code}]
+	return r, ok
+}
+
+// ByID returns the record of a device instance with the given data attribute ID.
+func (idx *DiagnosticsIndex) ByID(device string, instance uint, dataAttributeID uint) (*DiagnosticsRecord, bool) {
+	r, ok := idx.records[diagnosticsKey{device: device, instance: instance, id: dataAttributeID}]
+	return r, ok
+}
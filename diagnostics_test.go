@@ -0,0 +1,83 @@
+package vrm_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestDiagnosticsAccessors(t *testing.T) {
+	x := []byte(`{
+		"success": true,
+		"records": [{
+			"idSite": 1495, "timestamp": 1497056046, "Device": "Gateway", "instance": 0,
+			"idDataAttribute": 1, "code": "g", "description": "gatewayID", "formatWithUnit": "%s",
+			"dbusServiceType": null, "dbusPath": null, "formattedValue": "Venus",
+			"dataAttributeEnumValues": [
+				{"nameEnum": "VGR, VGR2 or VER", "valueEnum": 0},
+				{"nameEnum": "Venus", "valueEnum": 1}
+			]
+		}, {
+			"idSite": 1495, "timestamp": 1497056046, "Device": "Battery Monitor", "instance": 288,
+			"idDataAttribute": 47, "code": "bv", "description": "Voltage", "formatWithUnit": "%.2F V",
+			"dbusServiceType": "battery", "dbusPath": "/Dc/0/Voltage", "rawValue": "12.81", "formattedValue": "12.81 V",
+			"dataAttributeEnumValues": []
+		}, {
+			"idSite": 1495, "timestamp": 1497056046, "Device": "Battery Monitor", "instance": 288,
+			"idDataAttribute": 51, "code": "bs", "description": "State of charge", "formatWithUnit": "%.1F %%",
+			"dbusServiceType": "battery", "dbusPath": "/Soc", "rawValue": 95.5, "formattedValue": "95.5 %",
+			"dataAttributeEnumValues": []
+		}, {
+			"idSite": 1495, "timestamp": 1497056046, "Device": "Solar Charger", "instance": 0,
+			"idDataAttribute": 85, "code": "ScS", "description": "Charge state", "formatWithUnit": "%s",
+			"rawValue": "3", "formattedValue": "Bulk",
+			"dataAttributeEnumValues": [{"nameEnum": "Off", "valueEnum": 0}, {"nameEnum": "Bulk", "valueEnum": 3}]
+		}],
+		"num_records": 4
+	}`)
+	diag := vrm.DiagnosticsResponse{}
+	if !assert.NoError(t, json.Unmarshal(x, &diag)) {
+		return
+	}
+
+	idx := diag.Index()
+
+	gateway, ok := idx.ByID("Gateway", 0, 1)
+	if assert.True(t, ok) {
+		name, ok := gateway.EnumName()
+		assert.True(t, ok)
+		assert.Equal(t, "Venus", name)
+		assert.Equal(t, "", gateway.Unit())
+		assert.Equal(t, "", gateway.DBusPathName())
+	}
+
+	voltage, ok := idx.ByCode("Battery Monitor", 288, "bv")
+	if assert.True(t, ok) {
+		v, ok := voltage.Value()
+		assert.True(t, ok)
+		assert.Equal(t, 12.81, v)
+		assert.Equal(t, "V", voltage.Unit())
+		assert.Equal(t, "battery", voltage.DBusServiceTypeName())
+		assert.Equal(t, "/Dc/0/Voltage", voltage.DBusPathName())
+		_, ok = voltage.EnumName()
+		assert.False(t, ok)
+	}
+
+	soc, ok := idx.ByCode("Battery Monitor", 288, "bs")
+	if assert.True(t, ok) {
+		assert.Equal(t, "%", soc.Unit())
+		assert.Equal(t, "95.5 %", soc.Text())
+	}
+
+	state, ok := idx.ByCode("Solar Charger", 0, "ScS")
+	if assert.True(t, ok) {
+		name, _ := state.EnumName()
+		assert.Equal(t, "Bulk", name)
+	}
+
+	_, ok = idx.ByCode("Battery Monitor", 289, "bv")
+	assert.False(t, ok)
+}
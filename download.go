@@ -0,0 +1,101 @@
+package vrm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type DownloadFormat string
+
+const (
+	DownloadFormatCSV  DownloadFormat = "csv"
+	DownloadFormatXLSX DownloadFormat = "xlsx"
+)
+
+type DownloadDatatype string
+
+const (
+	DatatypeLog DownloadDatatype = "log"
+	DatatypeKwh DownloadDatatype = "kwh"
+)
+
+// DownloadQuery selects the period, format and kind of data to export. Zero values are left to VRM's defaults.
+type DownloadQuery struct {
+	Start    time.Time
+	End      time.Time
+	Format   DownloadFormat
+	Datatype DownloadDatatype
+	Debug    bool
+}
+
+func (q DownloadQuery) values() interface{} {
+	v := struct {
+		Start    int64            `url:"start,omitempty"`
+		End      int64            `url:"end,omitempty"`
+		Format   DownloadFormat   `url:"format,omitempty"`
+		Datatype DownloadDatatype `url:"datatype,omitempty"`
+		Debug    bool             `url:"debug,int,omitempty"`
+	}{
+		Format:   q.Format,
+		Datatype: q.Datatype,
+		Debug:    q.Debug,
+	}
+	if !q.Start.IsZero() {
+		v.Start = q.Start.Unix()
+	}
+	if !q.End.IsZero() {
+		v.End = q.End.Unix()
+	}
+	return v
+}
+
+// Retrieve a CSV export of the kWh data of the last 12 months
+func (s *vrmSession) DownloadData(siteID int) ([]byte, error) {
+	return s.DownloadDataContext(context.Background(), siteID)
+}
+
+// DownloadDataContext is like DownloadData but carries a context.
+func (s *vrmSession) DownloadDataContext(ctx context.Context, siteID int) ([]byte, error) {
+	now := time.Now()
+	var data bytes.Buffer
+	if _, err := s.DownloadDataToContext(ctx, &data, siteID, DownloadQuery{
+		Start:    now.AddDate(0, -12, 0),
+		End:      now,
+		Format:   DownloadFormatCSV,
+		Datatype: DatatypeKwh,
+	}); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+// Stream an export of installation data as selected by the query to w. Returns the number of bytes written.
+func (s *vrmSession) DownloadDataTo(w io.Writer, siteID int, query DownloadQuery) (int64, error) {
+	return s.DownloadDataToContext(context.Background(), w, siteID, query)
+}
+
+// DownloadDataToContext is like DownloadDataTo but carries a context.
+func (s *vrmSession) DownloadDataToContext(ctx context.Context, w io.Writer, siteID int, query DownloadQuery) (int64, error) {
+	url, err := s.formatURL(downloadURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, query.values())
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := s.idempotentRequest(ctx, url)
+	if err != nil {
+		return 0, fmt.Errorf("request for downloading data failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	n, err := io.Copy(w, res.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to read downloaded data: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,90 @@
+package vrm_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+const csvExport = `timestamp,Gateway,Battery Monitor,Battery Monitor
+,VRM Log time offset,Voltage,State of charge
+,s,V,%
+2020-09-01 00:00:00,0,12.81,95.5
+2020-09-01 00:15:00,0,12.79,
+`
+
+func TestDownloadDataTo(t *testing.T) {
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/installations/1234/data-download", r.URL.Path)
+		assert.Equal(t, "1598918400", r.URL.Query().Get("start"))
+		assert.Equal(t, "log", r.URL.Query().Get("datatype"))
+		assert.Equal(t, "csv", r.URL.Query().Get("format"))
+		assert.Empty(t, r.URL.Query().Get("debug"))
+		fmt.Fprint(w, csvExport)
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	n, err := session.DownloadDataTo(&buf, 1234, vrm.DownloadQuery{
+		Start:    time.Unix(1598918400, 0),
+		Format:   vrm.DownloadFormatCSV,
+		Datatype: vrm.DatatypeLog,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(len(csvExport)), n)
+		assert.Equal(t, csvExport, buf.String())
+	}
+}
+
+func TestParseCSVExport(t *testing.T) {
+	header, rows, err := vrm.ParseCSVExport(strings.NewReader(csvExport), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{
+		"timestamp",
+		"Gateway VRM Log time offset s",
+		"Battery Monitor Voltage V",
+		"Battery Monitor State of charge %",
+	}, header)
+
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, time.Date(2020, 9, 1, 0, 15, 0, 0, time.UTC), rows[1].Time)
+
+		voltage, ok := rows[0].Float("Battery Monitor Voltage V")
+		assert.True(t, ok)
+		assert.Equal(t, 12.81, voltage)
+
+		_, ok = rows[1].Float("Battery Monitor State of charge %")
+		assert.False(t, ok)
+	}
+}
+
+func TestParseCSVExportDuplicateHeaders(t *testing.T) {
+	export := "timestamp,Battery Monitor,Battery Monitor\n" +
+		",Voltage,Voltage\n" +
+		",V,V\n" +
+		"1598918400,12.81,25.6\n"
+	header, rows, err := vrm.ParseCSVExport(strings.NewReader(export), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"timestamp", "Battery Monitor Voltage V", "Battery Monitor Voltage V (2)"}, header)
+	if assert.Len(t, rows, 1) {
+		assert.Equal(t, "12.81", rows[0].Values["Battery Monitor Voltage V"])
+		assert.Equal(t, "25.6", rows[0].Values["Battery Monitor Voltage V (2)"])
+	}
+}
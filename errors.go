@@ -0,0 +1,111 @@
+package vrm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Upper bound of an error body which is kept in an APIError
+const maxErrorBodySize = 64 * 1024
+
+// APIError is returned for any non-2xx response of the VRM API.
+type APIError struct {
+	StatusCode int
+	ErrorCode  string
+	Message    string
+	Body       []byte
+	// Delay requested by the server via Retry-After, if any
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("http error: %d", e.StatusCode)
+	if len(e.ErrorCode) > 0 {
+		msg += " (" + e.ErrorCode + ")"
+	}
+	if len(e.Message) > 0 {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// newAPIError consumes the body of an unsuccessful response and decodes VRM's error details from it.
+func newAPIError(res *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	if err != nil && len(body) == 0 {
+		return apiErr
+	}
+	apiErr.Body = body
+
+	data := struct {
+		Success   bool            `json:"success"`
+		Errors    json.RawMessage `json:"errors"`
+		ErrorCode string          `json:"error_code"`
+	}{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return apiErr
+	}
+	apiErr.ErrorCode = data.ErrorCode
+	apiErr.Message = errorMessage(data.Errors)
+
+	return apiErr
+}
+
+// errorMessage flattens VRM's "errors" field which is either a string or an object of field errors.
+func errorMessage(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var msg string
+	if err := json.Unmarshal(raw, &msg); err == nil {
+		return msg
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err == nil {
+		msgs := make([]string, 0, len(fields))
+		for field, v := range fields {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", field, v))
+		}
+		return strings.Join(msgs, "; ")
+	}
+
+	return string(raw)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// IsUnauthorized reports whether err was caused by a missing, invalid or expired token.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err was caused by insufficient access rights.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound reports whether err was caused by an unknown resource, e.g. a wrong site ID.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsRateLimited reports whether err was caused by VRM throttling requests.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
@@ -0,0 +1,52 @@
+package vrm_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"success": false, "errors": "Token is expired", "error_code": "invalid_token"}`)
+	}))
+	defer server.Close()
+
+	_, err := vrm.NewSessionWithAccessToken(0, "expired", vrm.WithBaseURL(server.URL))
+	if assert.Error(t, err) {
+		assert.True(t, vrm.IsUnauthorized(err))
+		assert.False(t, vrm.IsNotFound(err))
+		assert.False(t, vrm.IsRateLimited(err))
+
+		var apiErr *vrm.APIError
+		if assert.True(t, errors.As(err, &apiErr)) {
+			assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+			assert.Equal(t, "invalid_token", apiErr.ErrorCode)
+			assert.Equal(t, "Token is expired", apiErr.Message)
+			assert.Contains(t, string(apiErr.Body), "Token is expired")
+		}
+	}
+}
+
+func TestAPIErrorLargeBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, strings.Repeat("x", 1<<20))
+	}))
+	defer server.Close()
+
+	_, err := vrm.NewSessionWithAccessToken(0, "token", vrm.WithBaseURL(server.URL))
+	var apiErr *vrm.APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Len(t, apiErr.Body, 64*1024, "the body is truncated")
+	}
+}
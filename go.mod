@@ -5,7 +5,7 @@ go 1.15
 require (
 	github.com/eclipse/paho.mqtt.golang v1.2.0
 	github.com/google/go-querystring v1.0.0
-	github.com/pkg/errors v0.8.1
 	github.com/rs/zerolog v1.20.0
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,233 @@
+package vrm
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GPSPoint is a single position of a GPS track. Time is zero if the track carries no timestamps.
+type GPSPoint struct {
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	// HasAltitude tells a known altitude of 0 from positions without altitude
+	HasAltitude bool
+}
+
+// GPSTrack is a sequence of positions as recorded by an installation's GPS.
+type GPSTrack []GPSPoint
+
+// Retrieve the GPS track of an installation for the given period
+func (s *vrmSession) GPSDownload(siteID int, start, end time.Time) (GPSTrack, error) {
+	return s.GPSDownloadContext(context.Background(), siteID, start, end)
+}
+
+// GPSDownloadContext is like GPSDownload but carries a context.
+func (s *vrmSession) GPSDownloadContext(ctx context.Context, siteID int, start, end time.Time) (GPSTrack, error) {
+	url, err := s.formatURL(gpsDownloadURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, struct {
+		Start int64 `url:"start"`
+		End   int64 `url:"end"`
+	}{start.Unix(), end.Unix()})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.idempotentRequest(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("request for downloading gps data failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	return ParseKML(res.Body)
+}
+
+// ParseKML reads the positions of all placemarks, tracks and line strings of a KML document as
+// delivered by the GPS download.
+func ParseKML(r io.Reader) (GPSTrack, error) {
+	var (
+		track    GPSTrack
+		path     []string
+		whens    []time.Time
+		when     time.Time
+		hasWhen  bool
+		inTrack  bool
+		trackLen int
+	)
+
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return track, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse kml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			switch t.Name.Local {
+			case "Placemark":
+				when, hasWhen = time.Time{}, false
+			case "Track":
+				inTrack, whens, trackLen = true, nil, len(track)
+			}
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+			if t.Name.Local == "Track" {
+				// Timestamps and coordinates of a track are listed separately in the same order
+				for i := 0; i < len(whens) && trackLen+i < len(track); i++ {
+					track[trackLen+i].Time = whens[i]
+				}
+				inTrack = false
+			}
+		case xml.CharData:
+			if len(path) == 0 {
+				continue
+			}
+			text := strings.TrimSpace(string(t))
+			if len(text) == 0 {
+				continue
+			}
+
+			switch path[len(path)-1] {
+			case "when":
+				ts, err := time.Parse(time.RFC3339, text)
+				if err != nil {
+					return nil, fmt.Errorf("invalid kml timestamp %q: %w", text, err)
+				}
+				if inTrack {
+					whens = append(whens, ts)
+				} else {
+					when, hasWhen = ts, true
+				}
+			case "coord":
+				p, err := parseKMLCoordinate(strings.Fields(text))
+				if err != nil {
+					return nil, err
+				}
+				track = append(track, p)
+			case "coordinates":
+				for _, tuple := range strings.Fields(text) {
+					p, err := parseKMLCoordinate(strings.Split(tuple, ","))
+					if err != nil {
+						return nil, err
+					}
+					if hasWhen {
+						p.Time = when
+					}
+					track = append(track, p)
+				}
+			}
+		}
+	}
+}
+
+// parseKMLCoordinate parses longitude, latitude and optional altitude.
+func parseKMLCoordinate(fields []string) (GPSPoint, error) {
+	if len(fields) < 2 {
+		return GPSPoint{}, fmt.Errorf("invalid kml coordinate %q", strings.Join(fields, ","))
+	}
+
+	values := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return GPSPoint{}, fmt.Errorf("invalid kml coordinate %q: %w", strings.Join(fields, ","), err)
+		}
+		values[i] = v
+	}
+
+	p := GPSPoint{Longitude: values[0], Latitude: values[1]}
+	if len(values) > 2 {
+		p.Altitude = values[2]
+		p.HasAltitude = true
+	}
+	return p, nil
+}
+
+// WriteGPX renders the track as GPX 1.1 document with a single track segment.
+func (t GPSTrack) WriteGPX(w io.Writer, name string) error {
+	type trkpt struct {
+		Lat float64 `xml:"lat,attr"`
+		Lon float64 `xml:"lon,attr"`
+		// Left out for positions without altitude rather than claiming sea level
+		Ele  *float64 `xml:"ele,omitempty"`
+		Time string   `xml:"time,omitempty"`
+	}
+	doc := struct {
+		XMLName xml.Name `xml:"gpx"`
+		Version string   `xml:"version,attr"`
+		Creator string   `xml:"creator,attr"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Track   struct {
+			Name   string  `xml:"name,omitempty"`
+			Points []trkpt `xml:"trkseg>trkpt"`
+		} `xml:"trk"`
+	}{
+		Version: "1.1",
+		Creator: "go-victron",
+		XMLNS:   "http://www.topografix.com/GPX/1/1",
+	}
+	doc.Track.Name = name
+	for i, p := range t {
+		pt := trkpt{Lat: p.Latitude, Lon: p.Longitude}
+		if p.HasAltitude {
+			pt.Ele = &t[i].Altitude
+		}
+		if !p.Time.IsZero() {
+			pt.Time = p.Time.UTC().Format(time.RFC3339)
+		}
+		doc.Track.Points = append(doc.Track.Points, pt)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// GeoJSON renders the track as GeoJSON feature with a LineString geometry. Timestamps are kept
+// in the "times" property, positions without altitude have two coordinates only.
+func (t GPSTrack) GeoJSON() ([]byte, error) {
+	coordinates := make([][]float64, len(t))
+	times := make([]string, len(t))
+	for i, p := range t {
+		coordinates[i] = []float64{p.Longitude, p.Latitude}
+		if p.HasAltitude {
+			coordinates[i] = append(coordinates[i], p.Altitude)
+		}
+		if !p.Time.IsZero() {
+			times[i] = p.Time.UTC().Format(time.RFC3339)
+		}
+	}
+
+	type geometry struct {
+		Type        string      `json:"type"`
+		Coordinates [][]float64 `json:"coordinates"`
+	}
+	return json.Marshal(struct {
+		Type       string                 `json:"type"`
+		Geometry   geometry               `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}{
+		Type:       "Feature",
+		Geometry:   geometry{Type: "LineString", Coordinates: coordinates},
+		Properties: map[string]interface{}{"times": times},
+	})
+}
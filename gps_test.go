@@ -0,0 +1,83 @@
+package vrm_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+const gpsKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+<Document>
+	<Placemark>
+		<gx:Track>
+			<when>2020-09-01T10:00:00Z</when>
+			<when>2020-09-01T10:15:00Z</when>
+			<gx:coord>5.12 52.09 2.5</gx:coord>
+			<gx:coord>5.14 52.10 3</gx:coord>
+		</gx:Track>
+	</Placemark>
+	<Placemark>
+		<TimeStamp><when>2020-09-01T10:30:00Z</when></TimeStamp>
+		<Point><coordinates>5.16,52.11,0</coordinates></Point>
+	</Placemark>
+	<Placemark>
+		<TimeStamp><when>2020-09-01T10:45:00Z</when></TimeStamp>
+		<Point><coordinates>5.18,52.12</coordinates></Point>
+	</Placemark>
+</Document>
+</kml>`
+
+func TestGPSDownload(t *testing.T) {
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/installations/1234/gps-download", r.URL.Path)
+		assert.Equal(t, "1598954400", r.URL.Query().Get("start"))
+		fmt.Fprint(w, gpsKML)
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	track, err := session.GPSDownload(1234, start, start.Add(time.Hour))
+	if !assert.NoError(t, err) || !assert.Len(t, track, 4) {
+		return
+	}
+	assert.Equal(t, vrm.GPSPoint{Time: start.Add(time.Minute * 15), Latitude: 52.10, Longitude: 5.14, Altitude: 3, HasAltitude: true}, track[1])
+	assert.Equal(t, start.Add(time.Minute*30), track[2].Time)
+	assert.True(t, track[2].HasAltitude, "an explicit altitude of 0 is known")
+	assert.False(t, track[3].HasAltitude)
+
+	var gpx bytes.Buffer
+	if assert.NoError(t, track.WriteGPX(&gpx, "Trip")) {
+		assert.Contains(t, gpx.String(), `<trkpt lat="52.09" lon="5.12">`)
+		assert.Contains(t, gpx.String(), `<time>2020-09-01T10:30:00Z</time>`)
+		assert.Contains(t, gpx.String(), `<ele>3</ele>`)
+		assert.Contains(t, gpx.String(), `<ele>0</ele>`)
+		assert.Equal(t, 3, strings.Count(gpx.String(), "<ele>"), "unknown altitudes are left out")
+	}
+
+	data, err := track.GeoJSON()
+	if assert.NoError(t, err) {
+		feature := struct {
+			Geometry struct {
+				Type        string      `json:"type"`
+				Coordinates [][]float64 `json:"coordinates"`
+			} `json:"geometry"`
+		}{}
+		assert.NoError(t, json.Unmarshal(data, &feature))
+		assert.Equal(t, "LineString", feature.Geometry.Type)
+		assert.Equal(t, []float64{5.16, 52.11, 0}, feature.Geometry.Coordinates[2])
+		assert.Equal(t, []float64{5.18, 52.12}, feature.Geometry.Coordinates[3])
+	}
+}
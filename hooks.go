@@ -0,0 +1,258 @@
+package vrm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Templates of all API URLs, used for naming requests in logs and hooks
+var urlTemplates = []string{
+	usersURL,
+	loginURL, logoutURL, loginAsDemoURL,
+	userMeURL, installationsURL, accessTokensListURL, accessTokensCreateURL, accessTokensRevokeURL,
+	systemOverviewURL, diagnosticsURL, tagsURL, downloadURL, gpsDownloadURL, statsURL, widgetsURL,
+	settingsURL, siteUsersURL, inviteUserURL, userRightsURL, unlinkUserURL, alarmsURL, alarmLogURL,
+}
+
+// Query parameters whose values are redacted in logs and hooks
+var redactedQueryParams = []string{"password", "sms_token", "token"}
+
+type route struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+var (
+	routesOnce sync.Once
+	routes     []route
+)
+
+var templateParam = regexp.MustCompile(`{{\s*\.(\w+)\s*}}`)
+
+// compileRoutes turns the URL templates into patterns matching paths relative to the base URL.
+// A template like "installations/{{ .siteID }}/stats" is named "installations/{siteID}/stats".
+func compileRoutes() {
+	for _, tpl := range urlTemplates {
+		tpl = strings.TrimPrefix(tpl, "{{ .baseURL }}")
+		literals := templateParam.Split(tpl, -1)
+		for i := range literals {
+			literals[i] = regexp.QuoteMeta(literals[i])
+		}
+		routes = append(routes, route{
+			name:    templateParam.ReplaceAllString(tpl, "{$1}"),
+			pattern: regexp.MustCompile("^" + strings.Join(literals, "[^/]+") + "$"),
+		})
+	}
+}
+
+// routeOf returns the name of the URL template the URL was formatted from, or its path relative
+// to the base URL if it matches none.
+func routeOf(u *url.URL, baseURL string) string {
+	routesOnce.Do(compileRoutes)
+
+	path := u.Path
+	if base, err := url.Parse(baseURL); err == nil {
+		path = strings.TrimPrefix(path, base.Path)
+	}
+	for _, r := range routes {
+		if r.pattern.MatchString(path) {
+			return r.name
+		}
+	}
+	return path
+}
+
+// redactQuery returns the encoded query with the values of secret parameters replaced.
+func redactQuery(query url.Values) string {
+	for _, param := range redactedQueryParams {
+		if _, ok := query[param]; ok {
+			query.Set(param, "REDACTED")
+		}
+	}
+	return query.Encode()
+}
+
+// RequestInfo describes a request to the VRM API as passed to hooks.
+type RequestInfo struct {
+	Method string
+	// Template of the URL relative to the base URL, e.g. "installations/{siteID}/stats", suitable
+	// as span or metric name
+	Route string
+	// Route with the query of the request, secrets redacted
+	URL string
+	// The request about to be sent; hooks may add headers, e.g. to propagate a trace
+	Request *http.Request
+}
+
+// ResponseInfo describes the outcome of a request to the VRM API as passed to hooks.
+type ResponseInfo struct {
+	// Zero if no response was received
+	StatusCode int
+	// Number of bytes of the response body read by the session
+	Size int64
+	// Time until the response headers arrived
+	Latency time.Duration
+	// Time until the response body was closed
+	Duration time.Duration
+	// Error sending the request or *APIError for an unsuccessful response
+	Err error
+}
+
+// Hooks are called around every request sent by a session, including retries and re-logins.
+type Hooks struct {
+	// BeforeRequest is called before the request is sent. The returned context is used for the request
+	// and passed to AfterRequest, e.g. to carry a span. It may be nil.
+	BeforeRequest func(ctx context.Context, req *RequestInfo) context.Context
+	// AfterRequest is called once the response body was closed or sending the request failed. It may be nil.
+	AfterRequest func(ctx context.Context, req *RequestInfo, res *ResponseInfo)
+}
+
+// WithHooks adds hooks to the session. Hooks added first are the outermost: their BeforeRequest is
+// called first and their AfterRequest last.
+func WithHooks(hooks Hooks) Option {
+	return func(s *vrmSession) {
+		s.hooks = append(s.hooks, hooks)
+	}
+}
+
+// WithLogger logs every request with its method, URL template, status, latency and response size.
+// Successful requests are logged at debug level, failed ones at warn level.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(s *vrmSession) {
+		s.logger = &logger
+	}
+}
+
+// observedRequest tracks a request sent by a session for its hooks and logger.
+type observedRequest struct {
+	session *vrmSession
+	ctx     context.Context
+	info    RequestInfo
+	start   time.Time
+	latency time.Duration
+	status  int
+	err     error
+}
+
+// observe prepares the request for hooks and logging. It returns nil if the session has neither.
+func (s *vrmSession) observe(ctx context.Context, req *http.Request) (*observedRequest, *http.Request) {
+	if len(s.hooks) == 0 && s.logger == nil {
+		return nil, req
+	}
+
+	route := routeOf(req.URL, s.baseURL)
+	info := RequestInfo{
+		Method: req.Method,
+		Route:  route,
+		URL:    route,
+	}
+	if query := req.URL.Query(); len(query) > 0 {
+		info.URL += "?" + redactQuery(query)
+	}
+
+	for _, hooks := range s.hooks {
+		if hooks.BeforeRequest == nil {
+			continue
+		}
+		info.Request = req
+		if hookCtx := hooks.BeforeRequest(ctx, &info); hookCtx != nil {
+			ctx = hookCtx
+			req = req.WithContext(ctx)
+		}
+	}
+	info.Request = req
+
+	return &observedRequest{session: s, ctx: ctx, info: info, start: time.Now()}, req
+}
+
+// received records the arrival of the response headers.
+func (o *observedRequest) received(res *http.Response) {
+	if o == nil {
+		return
+	}
+	o.latency = time.Since(o.start)
+	o.status = res.StatusCode
+}
+
+// failed records the error the request failed with.
+func (o *observedRequest) failed(err error) {
+	if o == nil {
+		return
+	}
+	o.err = err
+}
+
+// done calls the hooks and logs the request once it is complete.
+func (o *observedRequest) done(size int64) {
+	if o == nil {
+		return
+	}
+	res := ResponseInfo{
+		StatusCode: o.status,
+		Size:       size,
+		Latency:    o.latency,
+		Duration:   time.Since(o.start),
+		Err:        o.err,
+	}
+
+	hooks := o.session.hooks
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].AfterRequest != nil {
+			hooks[i].AfterRequest(o.ctx, &o.info, &res)
+		}
+	}
+
+	if o.session.logger == nil {
+		return
+	}
+	event := o.session.logger.Debug()
+	if res.Err != nil {
+		event = o.session.logger.Warn().Err(res.Err)
+	}
+	event.
+		Str("method", o.info.Method).
+		Str("route", o.info.Route).
+		Str("url", o.info.URL).
+		Int("status", res.StatusCode).
+		Dur("latency", res.Latency).
+		Dur("duration", res.Duration).
+		Int64("size", res.Size).
+		Msg("vrm request")
+}
+
+// body wraps a response body to report the request as done once the body is closed.
+func (o *observedRequest) body(rc io.ReadCloser) io.ReadCloser {
+	if o == nil {
+		return rc
+	}
+	return &observedBody{ReadCloser: rc, request: o}
+}
+
+type observedBody struct {
+	io.ReadCloser
+	request *observedRequest
+	size    int64
+	once    sync.Once
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.request.done(b.size)
+	})
+	return err
+}
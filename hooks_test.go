@@ -0,0 +1,121 @@
+package vrm_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func hooksServer(traceparents *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*traceparents = append(*traceparents, r.Header.Get("traceparent"))
+		if demoLogin(w, r) {
+			return
+		}
+		switch r.URL.Path {
+		case "/installations/1234/system-overview":
+			fmt.Fprint(w, `{"success": true, "records": {"devices": []}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success": false, "errors": "Not found", "error_code": "not_found"}`)
+		}
+	}))
+}
+
+func TestWithLogger(t *testing.T) {
+	var traceparents []string
+	server := hooksServer(&traceparents)
+	defer server.Close()
+
+	buf := new(bytes.Buffer)
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL), vrm.WithLogger(zerolog.New(buf)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = session.SystemOverview(1234)
+	assert.NoError(t, err)
+	_, err = session.Diagnostics(42, 10)
+	assert.True(t, vrm.IsNotFound(err))
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]interface{}{}
+		if assert.NoError(t, json.Unmarshal([]byte(line), &entry)) {
+			entries = append(entries, entry)
+		}
+	}
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+
+	assert.Equal(t, "auth/loginAsDemo", entries[0]["route"])
+
+	assert.Equal(t, "debug", entries[1]["level"])
+	assert.Equal(t, "GET", entries[1]["method"])
+	assert.Equal(t, "installations/{siteID}/system-overview", entries[1]["route"])
+	assert.Equal(t, float64(200), entries[1]["status"])
+	assert.Greater(t, entries[1]["size"], float64(0))
+
+	assert.Equal(t, "warn", entries[2]["level"])
+	assert.Equal(t, "installations/{siteID}/diagnostics", entries[2]["route"])
+	assert.Equal(t, "installations/{siteID}/diagnostics?count=10", entries[2]["url"])
+	assert.Equal(t, float64(404), entries[2]["status"])
+	assert.Contains(t, entries[2]["error"], "Not found")
+}
+
+type spanKey struct{}
+
+func TestWithHooks(t *testing.T) {
+	var traceparents []string
+	server := hooksServer(&traceparents)
+	defer server.Close()
+
+	var order []string
+	var responses []vrm.ResponseInfo
+	tracing := vrm.Hooks{
+		BeforeRequest: func(ctx context.Context, req *vrm.RequestInfo) context.Context {
+			order = append(order, "before tracing")
+			req.Request.Header.Set("traceparent", "00-trace-span-01")
+			return context.WithValue(ctx, spanKey{}, req.Route)
+		},
+		AfterRequest: func(ctx context.Context, req *vrm.RequestInfo, res *vrm.ResponseInfo) {
+			order = append(order, "after tracing")
+			assert.Equal(t, req.Route, ctx.Value(spanKey{}))
+			responses = append(responses, *res)
+		},
+	}
+	metrics := vrm.Hooks{
+		AfterRequest: func(ctx context.Context, req *vrm.RequestInfo, res *vrm.ResponseInfo) {
+			order = append(order, "after metrics")
+		},
+	}
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL), vrm.WithHooks(tracing), vrm.WithHooks(metrics))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = session.SystemOverview(1234)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"00-trace-span-01", "00-trace-span-01"}, traceparents)
+	assert.Equal(t, []string{
+		"before tracing", "after metrics", "after tracing",
+		"before tracing", "after metrics", "after tracing",
+	}, order)
+	if assert.Len(t, responses, 2) {
+		assert.Equal(t, http.StatusOK, responses[1].StatusCode)
+		assert.Greater(t, responses[1].Size, int64(0))
+		assert.True(t, responses[1].Duration >= responses[1].Latency)
+		assert.NoError(t, responses[1].Err)
+	}
+}
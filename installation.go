@@ -0,0 +1,202 @@
+package vrm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Retrieve a single installation of the session's user including extended data
+func (s *vrmSession) Installation(siteID int) (*Installation, error) {
+	return s.InstallationContext(context.Background(), siteID)
+}
+
+// InstallationContext is like Installation but carries a context.
+func (s *vrmSession) InstallationContext(ctx context.Context, siteID int) (*Installation, error) {
+	url, err := s.formatURL(installationsURL, URLParams{
+		"UserID": strconv.Itoa(s.UserID()),
+	}, struct {
+		Extended uint8 `url:"extended"`
+		SiteID   int   `url:"idSite"`
+	}{1, siteID})
+	if err != nil {
+		return nil, err
+	}
+
+	data := InstallationsResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	for _, install := range data.Records {
+		if install.SiteID == siteID {
+			return &install, nil
+		}
+	}
+	// VRM lists no installation rather than failing for sites the user cannot access
+	return nil, &APIError{
+		StatusCode: http.StatusNotFound,
+		ErrorCode:  "not_found",
+		Message:    fmt.Sprintf("installation %d not found", siteID),
+	}
+}
+
+type InstallationSettings struct {
+	Name            string `json:"name"`
+	Timezone        string `json:"timezone"`
+	Geofence        string `json:"geofence,omitempty"`
+	GeofenceEnabled bool   `json:"geofenceEnabled"`
+	// 0 disables alarm monitoring, 1 notifies on alarms only, 2 on alarms and warnings
+	AlarmMonitoring     int  `json:"alarmMonitoring"`
+	NotifyOnConnectLoss bool `json:"notifyOnConnectLoss"`
+	ReportsEnabled      bool `json:"reports_enabled"`
+	// Hours without data before a connection loss is reported
+	ConnectLossHours int    `json:"connectLossHours,omitempty"`
+	Notes            string `json:"notes,omitempty"`
+}
+
+type InstallationSettingsResponse struct {
+	Success bool                 `json:"success"`
+	Data    InstallationSettings `json:"data"`
+}
+
+// Retrieve the settings of an installation
+func (s *vrmSession) InstallationSettings(siteID int) (*InstallationSettingsResponse, error) {
+	return s.InstallationSettingsContext(context.Background(), siteID)
+}
+
+// InstallationSettingsContext is like InstallationSettings but carries a context.
+func (s *vrmSession) InstallationSettingsContext(ctx context.Context, siteID int) (*InstallationSettingsResponse, error) {
+	url, err := s.formatURL(settingsURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := InstallationSettingsResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// Replace the settings of an installation. Retrieve the current settings first to change single values.
+func (s *vrmSession) UpdateInstallationSettings(siteID int, settings InstallationSettings) (*InstallationSettingsResponse, error) {
+	return s.UpdateInstallationSettingsContext(context.Background(), siteID, settings)
+}
+
+// UpdateInstallationSettingsContext is like UpdateInstallationSettings but carries a context.
+func (s *vrmSession) UpdateInstallationSettingsContext(ctx context.Context, siteID int, settings InstallationSettings) (*InstallationSettingsResponse, error) {
+	url, err := s.formatURL(settingsURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := InstallationSettingsResponse{}
+	if err := s.postAndLoad(ctx, settings, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+type SiteUser struct {
+	UserID      int    `json:"idUser"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	AccessLevel int    `json:"accessLevel"`
+}
+
+type SiteInvite struct {
+	Email       string `json:"email"`
+	Name        string `json:"name,omitempty"`
+	AccessLevel int    `json:"accessLevel"`
+}
+
+type SiteUsersResponse struct {
+	Success bool         `json:"success"`
+	Users   []SiteUser   `json:"users"`
+	Invites []SiteInvite `json:"invites"`
+}
+
+// List the users having access to an installation and the pending invites
+func (s *vrmSession) SiteUsers(siteID int) (*SiteUsersResponse, error) {
+	return s.SiteUsersContext(context.Background(), siteID)
+}
+
+// SiteUsersContext is like SiteUsers but carries a context.
+func (s *vrmSession) SiteUsersContext(ctx context.Context, siteID int) (*SiteUsersResponse, error) {
+	url, err := s.formatURL(siteUsersURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := SiteUsersResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+type SiteUserResponse struct {
+	Success bool `json:"success"`
+}
+
+// Invite a user by email to access an installation with the given access level
+func (s *vrmSession) InviteUser(siteID int, invite SiteInvite) (*SiteUserResponse, error) {
+	return s.InviteUserContext(context.Background(), siteID, invite)
+}
+
+// InviteUserContext is like InviteUser but carries a context.
+func (s *vrmSession) InviteUserContext(ctx context.Context, siteID int, invite SiteInvite) (*SiteUserResponse, error) {
+	return s.changeSiteUser(ctx, inviteUserURL, siteID, invite)
+}
+
+// Change the access level of a user of an installation
+func (s *vrmSession) SetUserAccessLevel(siteID int, userID int, accessLevel int) (*SiteUserResponse, error) {
+	return s.SetUserAccessLevelContext(context.Background(), siteID, userID, accessLevel)
+}
+
+// SetUserAccessLevelContext is like SetUserAccessLevel but carries a context.
+func (s *vrmSession) SetUserAccessLevelContext(ctx context.Context, siteID int, userID int, accessLevel int) (*SiteUserResponse, error) {
+	return s.changeSiteUser(ctx, userRightsURL, siteID, struct {
+		UserID      int `json:"idUser"`
+		AccessLevel int `json:"accessLevel"`
+	}{userID, accessLevel})
+}
+
+// Revoke the access of a user to an installation
+func (s *vrmSession) RemoveUser(siteID int, userID int) (*SiteUserResponse, error) {
+	return s.RemoveUserContext(context.Background(), siteID, userID)
+}
+
+// RemoveUserContext is like RemoveUser but carries a context.
+func (s *vrmSession) RemoveUserContext(ctx context.Context, siteID int, userID int) (*SiteUserResponse, error) {
+	return s.changeSiteUser(ctx, unlinkUserURL, siteID, struct {
+		UserID int `json:"idUser"`
+	}{userID})
+}
+
+func (s *vrmSession) changeSiteUser(ctx context.Context, urlTemplate string, siteID int, reqData interface{}) (*SiteUserResponse, error) {
+	url, err := s.formatURL(urlTemplate, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := SiteUserResponse{}
+	if err := s.postAndLoad(ctx, reqData, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
@@ -0,0 +1,80 @@
+package vrm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestInstallationManagement(t *testing.T) {
+	posted := map[string]map[string]interface{}{}
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path != "/auth/login" {
+			body := map[string]interface{}{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			posted[r.URL.Path] = body
+		}
+
+		switch r.URL.Path {
+		case "/users/22/installations":
+			if r.URL.Query().Get("idSite") != "1234" {
+				fmt.Fprint(w, `{"success": true, "records": []}`)
+				return
+			}
+			fmt.Fprint(w, `{"success": true, "records": [{"idSite": 1234, "name": "Boat", "timezone": "Europe/Amsterdam"}]}`)
+		case "/installations/1234/settings":
+			fmt.Fprint(w, `{"success": true, "data": {"name": "Boat", "timezone": "Europe/Amsterdam", "alarmMonitoring": 1}}`)
+		case "/installations/1234/users":
+			fmt.Fprint(w, `{"success": true,
+				"users": [{"idUser": 22, "name": "Demo", "email": "demo@example.com", "accessLevel": 1}],
+				"invites": [{"email": "new@example.com", "accessLevel": 2}]}`)
+		default:
+			fmt.Fprint(w, `{"success": true}`)
+		}
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	install, err := session.Installation(1234)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Boat", install.Name)
+	}
+	_, err = session.Installation(42)
+	assert.True(t, vrm.IsNotFound(err))
+
+	settings, err := session.InstallationSettings(1234)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, settings.Data.AlarmMonitoring)
+
+		settings.Data.Timezone = "Europe/Berlin"
+		_, err = session.UpdateInstallationSettings(1234, settings.Data)
+		assert.NoError(t, err)
+		assert.Equal(t, "Europe/Berlin", posted["/installations/1234/settings"]["timezone"])
+	}
+
+	users, err := session.SiteUsers(1234)
+	if assert.NoError(t, err) {
+		assert.Len(t, users.Users, 1)
+		assert.Len(t, users.Invites, 1)
+	}
+
+	_, err = session.InviteUser(1234, vrm.SiteInvite{Email: "installer@example.com", AccessLevel: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "installer@example.com", posted["/installations/1234/invite-user"]["email"])
+
+	_, err = session.SetUserAccessLevel(1234, 23, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), posted["/installations/1234/set-user-rights"]["accessLevel"])
+
+	_, err = session.RemoveUser(1234, 23)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(23), posted["/installations/1234/unlink-user"]["idUser"])
+}
@@ -0,0 +1,152 @@
+package vrm
+
+import (
+	"context"
+	"strconv"
+)
+
+// Number of installations per page requested by the iterator
+const defaultInstallationsPageSize = 100
+
+// InstallationsQuery selects the installations walked by AllInstallations. Unset filters match all.
+type InstallationsQuery struct {
+	// Users whose installations are listed; defaults to the session's user
+	UserIDs  []int
+	Extended bool
+	// Page size, defaults to 100
+	Count int
+
+	Owner       *bool
+	AccessLevel *int
+	Alarm       *bool
+	Tag         string
+}
+
+func (q *InstallationsQuery) matches(i *Installation) bool {
+	return (q.Owner == nil || *q.Owner == i.Owner) &&
+		(q.AccessLevel == nil || *q.AccessLevel == i.AccessLevel) &&
+		(q.Alarm == nil || *q.Alarm == i.Alarm) &&
+		(len(q.Tag) == 0 || i.HasTag(q.Tag))
+}
+
+// InstallationIterator walks all pages of installations of one or more users. Installations shared
+// between users are returned only once. Use it like UserIterator.
+type InstallationIterator struct {
+	ctx   context.Context
+	fetch InstallationsPageFunc
+	query InstallationsQuery
+	// Index into the query's users and page of the current user
+	user int
+	page int
+	// Sites already returned
+	seen map[int]bool
+	// Sites listed for the current user, to detect repeated pages
+	listed map[int]bool
+	buffer []Installation
+	err    error
+}
+
+// InstallationsPageFunc fetches a single page of a user's installations. Pages start at 1.
+type InstallationsPageFunc func(ctx context.Context, userID int, page int, count int, extended bool) ([]Installation, error)
+
+// NewInstallationIterator creates an iterator fetching pages by the given function, e.g. to iterate over
+// installations of a Session implementation other than the one returned by Login. The query must name
+// the users.
+func NewInstallationIterator(ctx context.Context, query InstallationsQuery, fetch InstallationsPageFunc) *InstallationIterator {
+	if query.Count < 1 {
+		query.Count = defaultInstallationsPageSize
+	}
+	return &InstallationIterator{ctx: ctx, fetch: fetch, query: query, page: 1, seen: map[int]bool{}, listed: map[int]bool{}}
+}
+
+// Iterate over all installations matching the query
+func (s *vrmSession) AllInstallations(query InstallationsQuery) *InstallationIterator {
+	return s.AllInstallationsContext(context.Background(), query)
+}
+
+// AllInstallationsContext is like AllInstallations but carries a context used for fetching all pages.
+func (s *vrmSession) AllInstallationsContext(ctx context.Context, query InstallationsQuery) *InstallationIterator {
+	if len(query.UserIDs) == 0 {
+		query.UserIDs = []int{s.UserID()}
+	}
+	return NewInstallationIterator(ctx, query, s.installationsPage)
+}
+
+// Next advances to the next matching installation, fetching further pages if necessary.
+func (it *InstallationIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+
+	for len(it.buffer) == 0 {
+		if it.user >= len(it.query.UserIDs) {
+			return false
+		}
+
+		records, err := it.fetch(it.ctx, it.query.UserIDs[it.user], it.page, it.query.Count, it.query.Extended)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		// Servers ignoring the paging parameters return everything at once or repeat the same page
+		fresh := 0
+		for i := range records {
+			install := &records[i]
+			if it.listed[install.SiteID] {
+				continue
+			}
+			it.listed[install.SiteID] = true
+			fresh++
+
+			// Owner and access level differ between users sharing a site, so a site not matching for
+			// one user may still match for another
+			if it.seen[install.SiteID] || !it.query.matches(install) {
+				continue
+			}
+			it.seen[install.SiteID] = true
+			it.buffer = append(it.buffer, *install)
+		}
+
+		if len(records) < it.query.Count || len(records) > it.query.Count || fresh == 0 {
+			it.user++
+			it.page = 1
+			it.listed = map[int]bool{}
+		} else {
+			it.page++
+		}
+	}
+	return true
+}
+
+func (s *vrmSession) installationsPage(ctx context.Context, userID int, page int, count int, extended bool) ([]Installation, error) {
+	url, err := s.formatURL(installationsURL, URLParams{
+		"UserID": strconv.Itoa(userID),
+	}, struct {
+		Extended bool `url:"extended,int,omitempty"`
+		Page     int  `url:"page"`
+		Count    int  `url:"count"`
+	}{extended, page, count})
+	if err != nil {
+		return nil, err
+	}
+
+	data := InstallationsResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+	return data.Records, nil
+}
+
+// Installation returns the current installation.
+func (it *InstallationIterator) Installation() Installation {
+	return it.buffer[0]
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *InstallationIterator) Err() error {
+	return it.err
+}
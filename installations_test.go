@@ -0,0 +1,58 @@
+package vrm_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestAllInstallations(t *testing.T) {
+	pages := map[string]string{
+		"/users/22/installations?count=2&page=1": `{"success": true, "records": [
+			{"idSite": 1, "name": "Boat", "owner": true, "alarm": true},
+			{"idSite": 2, "name": "House", "owner": true}
+		]}`,
+		"/users/22/installations?count=2&page=2": `{"success": true, "records": [
+			{"idSite": 3, "name": "Barn", "owner": false, "alarm": true}
+		]}`,
+		// Ignores paging and returns a site shared with user 22
+		"/users/23/installations?count=2&page=1": `{"success": true, "records": [
+			{"idSite": 3, "name": "Barn", "owner": true, "alarm": true},
+			{"idSite": 4, "name": "Shed", "owner": true, "alarm": true},
+			{"idSite": 5, "name": "Van", "owner": true}
+		]}`,
+	}
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path+"?"+r.URL.RawQuery]
+		assert.True(t, ok, r.URL.String())
+		fmt.Fprint(w, page)
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	collect := func(query vrm.InstallationsQuery) []int {
+		var sites []int
+		it := session.AllInstallations(query)
+		for it.Next() {
+			sites = append(sites, it.Installation().SiteID)
+		}
+		assert.NoError(t, it.Err())
+		return sites
+	}
+
+	assert.Equal(t, []int{1, 2, 3}, collect(vrm.InstallationsQuery{Count: 2}))
+
+	alarm := true
+	assert.Equal(t, []int{1, 3, 4}, collect(vrm.InstallationsQuery{Count: 2, UserIDs: []int{22, 23}, Alarm: &alarm}))
+
+	// Site 3 is shared with user 22 but owned by user 23 only
+	owner := true
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, collect(vrm.InstallationsQuery{Count: 2, UserIDs: []int{22, 23}, Owner: &owner}))
+}
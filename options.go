@@ -0,0 +1,41 @@
+package vrm
+
+import (
+	"strings"
+	"time"
+)
+
+// Option configures a session created by Login, LoginAsDemo or NewSessionWithAccessToken.
+type Option func(*vrmSession)
+
+// WithBaseURL points the session at another VRM API endpoint, e.g. a local mock server.
+func WithBaseURL(url string) Option {
+	return func(s *vrmSession) {
+		if !strings.HasSuffix(url, "/") {
+			url += "/"
+		}
+		s.baseURL = url
+	}
+}
+
+// WithHTTPClient replaces the default HTTP client, e.g. to go through a proxy.
+func WithHTTPClient(client HTTPClient) Option {
+	return func(s *vrmSession) {
+		s.Client = client
+	}
+}
+
+// WithTimeout sets the timeout of the session's HTTP client. It has no effect on custom clients
+// which are not an *http.Client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *vrmSession) {
+		s.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(s *vrmSession) {
+		s.userAgent = userAgent
+	}
+}
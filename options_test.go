@@ -0,0 +1,61 @@
+package vrm_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestSessionOptions(t *testing.T) {
+	var userAgents, authorizations []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/auth/loginAsDemo", func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		demoLogin(w, r)
+	})
+	mux.HandleFunc("/v2/users/22/installations", func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		authorizations = append(authorizations, r.Header.Get("X-Authorization"))
+		fmt.Fprint(w, `{"success": true, "records": [{"name": "Demo", "idSite": 1}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	session, err := vrm.LoginAsDemo(
+		vrm.WithBaseURL(server.URL+"/v2"),
+		vrm.WithHTTPClient(server.Client()),
+		vrm.WithUserAgent("vrm-test/1.0"),
+	)
+	if assert.NoError(t, err) {
+		installs, err := session.Installations(session.UserID())
+		if assert.NoError(t, err) {
+			assert.True(t, installs.Success)
+			assert.Len(t, installs.Records, 1)
+		}
+	}
+	assert.Equal(t, []string{"vrm-test/1.0", "vrm-test/1.0"}, userAgents)
+	assert.Equal(t, []string{"Bearer demo-token"}, authorizations)
+}
+
+func TestAccessTokenSession(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("X-Authorization")
+		fmt.Fprint(w, `{"success": true, "user": {"id": 42, "name": "Jane"}}`)
+	}))
+	defer server.Close()
+
+	session, err := vrm.NewSessionWithAccessToken(0, "secret", vrm.WithBaseURL(server.URL))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 42, session.UserID())
+		assert.Equal(t, "Token secret", authorization)
+	}
+
+	_, err = vrm.NewSessionWithAccessToken(7, "secret", vrm.WithBaseURL(server.URL))
+	assert.Error(t, err)
+}
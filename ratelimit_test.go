@@ -0,0 +1,55 @@
+package vrm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestTokenBucket(t *testing.T) {
+	bucket := vrm.NewTokenBucket(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, bucket.Wait(context.Background()))
+	}
+	// The burst is free, the two remaining requests need 10ms each
+	assert.True(t, time.Since(start) >= time.Millisecond*15)
+
+	slow := vrm.NewTokenBucket(0.001, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, slow.Wait(ctx), "burst is available immediately")
+	assert.Error(t, slow.Wait(ctx))
+
+	unlimited := vrm.NewTokenBucket(0, 1)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, unlimited.Wait(ctx), "a rate of zero disables the limit")
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "demo-token", "idUser": "22"}`)
+	}))
+	defer server.Close()
+
+	bucket := vrm.NewTokenBucket(0.001, 2)
+	for i := 0; i < 2; i++ {
+		_, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL), vrm.WithRateLimiter(bucket))
+		assert.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err := vrm.LoginAsDemoContext(ctx, vrm.WithBaseURL(server.URL), vrm.WithRateLimiter(bucket))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
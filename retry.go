@@ -0,0 +1,126 @@
+package vrm
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how idempotent requests are retried after being throttled or after transient failures.
+type RetryPolicy struct {
+	// Maximum number of attempts including the first one
+	MaxAttempts int
+	// Backoff before the first retry, doubled with every further retry
+	InitialBackoff time.Duration
+	// Upper bound of a single backoff, also applied to Retry-After
+	MaxBackoff time.Duration
+	// OnRetry is called before waiting for the next attempt, if set
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// DefaultRetryPolicy is a reasonable policy for bulk operations.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Second * 30,
+}
+
+// WithRetryPolicy enables retrying idempotent requests according to the given policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *vrmSession) {
+		s.retryPolicy = &policy
+	}
+}
+
+// backoff returns the time to wait before the given retry, honouring the server's Retry-After if present.
+func (p *RetryPolicy) backoff(retry int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if p.MaxBackoff > 0 && apiErr.RetryAfter > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+		return apiErr.RetryAfter
+	}
+
+	d := p.InitialBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	// Jitter between half and the full backoff to spread out concurrent clients
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// isRetryable reports whether a failed request may succeed when repeated.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// Transport errors like connection resets
+		return true
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses the Retry-After header given either in seconds or as HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// idempotentRequest performs a GET request and retries it according to the session's retry policy.
+// GET requests changing state must not be retried; see getOnceAndLoad.
+func (s *vrmSession) idempotentRequest(ctx context.Context, url string) (*http.Response, error) {
+	res, err := s.request(ctx, http.MethodGet, url, nil)
+	if s.retryPolicy == nil {
+		return res, err
+	}
+
+	for attempt := 1; err != nil && attempt < s.retryPolicy.MaxAttempts && isRetryable(err); attempt++ {
+		wait := s.retryPolicy.backoff(attempt, err)
+		if s.retryPolicy.OnRetry != nil {
+			s.retryPolicy.OnRetry(attempt, wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		res, err = s.request(ctx, http.MethodGet, url, nil)
+	}
+
+	return res, err
+}
@@ -0,0 +1,83 @@
+package vrm_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var calls int32
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(&calls, 1)
+		switch {
+		case call == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case call == 2:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{"success": true, "records": {"devices": []}}`)
+		}
+	})
+
+	var waits []time.Duration
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL), vrm.WithRetryPolicy(vrm.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond * 10,
+		OnRetry: func(attempt int, wait time.Duration, err error) {
+			waits = append(waits, wait)
+		},
+	}))
+	if assert.NoError(t, err) {
+		overview, err := session.SystemOverview(1)
+		if assert.NoError(t, err) {
+			assert.True(t, overview.Success)
+		}
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	if assert.Len(t, waits, 2) {
+		assert.True(t, waits[0] <= time.Millisecond)
+		// Retry-After is capped by MaxBackoff
+		assert.Equal(t, time.Millisecond*10, waits[1])
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL), vrm.WithRetryPolicy(vrm.DefaultRetryPolicy))
+	assert.True(t, vrm.IsNotFound(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryPolicySkipsStateChanges(t *testing.T) {
+	var revokes int32
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&revokes, 1)
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL), vrm.WithRetryPolicy(vrm.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}))
+	if assert.NoError(t, err) {
+		_, err = session.RevokeAccessToken("1234")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&revokes))
+}
@@ -0,0 +1,261 @@
+package vrm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Point is a single value of a stats time series. For plain series Min and Max equal Value.
+type Point struct {
+	Time  time.Time
+	Value float64
+	Min   float64
+	Max   float64
+}
+
+// UnmarshalJSON decodes VRM's [timestamp in ms, value(, min, max)] tuples.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var fields []*float64
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) < 2 || fields[0] == nil {
+		return fmt.Errorf("invalid stats point: %s", data)
+	}
+
+	*p = Point{Time: time.Unix(0, int64(*fields[0])*int64(time.Millisecond))}
+	if fields[1] != nil {
+		p.Value = *fields[1]
+	} else {
+		p.Value = math.NaN()
+	}
+	p.Min, p.Max = p.Value, p.Value
+	if len(fields) >= 4 && fields[2] != nil && fields[3] != nil {
+		p.Min, p.Max = *fields[2], *fields[3]
+	}
+	return nil
+}
+
+// Series is a time series of points ordered by time.
+type Series []Point
+
+// Sum adds up all values, e.g. to get the total energy of a kWh series.
+func (s Series) Sum() float64 {
+	sum := 0.0
+	for _, p := range s {
+		if !math.IsNaN(p.Value) {
+			sum += p.Value
+		}
+	}
+	return sum
+}
+
+type Aggregation int
+
+const (
+	// Sum values of a bucket, suitable for energy
+	AggregateSum Aggregation = iota
+	// Average values of a bucket, suitable for power, voltage etc.
+	AggregateMean
+)
+
+// Resample groups the points into buckets of the given size and aggregates each bucket into one point.
+// Buckets are aligned to multiples of the interval since the zero time, i.e. days start at midnight UTC.
+func (s Series) Resample(interval time.Duration, agg Aggregation) Series {
+	var resampled Series
+	var count int
+	for _, p := range s {
+		if math.IsNaN(p.Value) {
+			continue
+		}
+
+		t := p.Time.Truncate(interval)
+		last := len(resampled) - 1
+		if last < 0 || !resampled[last].Time.Equal(t) {
+			if last >= 0 && agg == AggregateMean {
+				resampled[last].Value /= float64(count)
+			}
+			resampled = append(resampled, Point{Time: t, Value: p.Value, Min: p.Min, Max: p.Max})
+			count = 1
+			continue
+		}
+
+		resampled[last].Value += p.Value
+		resampled[last].Min = math.Min(resampled[last].Min, p.Min)
+		resampled[last].Max = math.Max(resampled[last].Max, p.Max)
+		count++
+	}
+	if last := len(resampled) - 1; last >= 0 && agg == AggregateMean {
+		resampled[last].Value /= float64(count)
+	}
+	return resampled
+}
+
+// Align merges the given series on their timestamps. values[i][j] is the value of series j at times[i],
+// or NaN if that series has no point at that time.
+func Align(series ...Series) (times []time.Time, values [][]float64) {
+	index := map[int64]int{}
+	for _, s := range series {
+		for _, p := range s {
+			ts := p.Time.UnixNano()
+			if _, ok := index[ts]; !ok {
+				index[ts] = len(times)
+				times = append(times, p.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	for i, t := range times {
+		index[t.UnixNano()] = i
+	}
+
+	values = make([][]float64, len(times))
+	for i := range values {
+		values[i] = make([]float64, len(series))
+		for j := range values[i] {
+			values[i][j] = math.NaN()
+		}
+	}
+	for j, s := range series {
+		for _, p := range s {
+			values[index[p.Time.UnixNano()]][j] = p.Value
+		}
+	}
+	return times, values
+}
+
+// StatsRecords maps series codes (Pc, Bc, kwh, attribute codes, ...) to their series. Series requested with
+// show_instance are keyed by "<code>:<instance>".
+type StatsRecords map[string]Series
+
+func (r *StatsRecords) UnmarshalJSON(data []byte) error {
+	records := StatsRecords{}
+
+	// VRM returns an empty array instead of an object if there is no data at all
+	if trimmed := bytes.TrimSpace(data); bytes.Equal(trimmed, []byte("[]")) || bytes.Equal(trimmed, []byte("null")) {
+		*r = records
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for code, value := range raw {
+		value = bytes.TrimSpace(value)
+		switch {
+		case len(value) == 0 || value[0] == 'f' || value[0] == 'n':
+			// Codes without data are reported as false
+			records[code] = Series{}
+		case value[0] == '{':
+			var instances map[string]Series
+			if err := json.Unmarshal(value, &instances); err != nil {
+				return fmt.Errorf("could not decode series %s: %w", code, err)
+			}
+			for instance, series := range instances {
+				records[code+":"+instance] = series
+			}
+		default:
+			var series Series
+			if err := json.Unmarshal(value, &series); err != nil {
+				return fmt.Errorf("could not decode series %s: %w", code, err)
+			}
+			records[code] = series
+		}
+	}
+
+	*r = records
+	return nil
+}
+
+// Instance returns the series of a code for a device instance, as requested with show_instance.
+func (r StatsRecords) Instance(code string, instance int) Series {
+	return r[code+":"+strconv.Itoa(instance)]
+}
+
+// StatsTotals holds the totals over the requested period. Codes without data are left out and read as
+// zero from the fields.
+type StatsTotals struct {
+	Pb  float64 `json:"Pb"`
+	Pc  float64 `json:"Pc"`
+	Gb  float64 `json:"Gb"`
+	Gc  float64 `json:"Gc"`
+	Pg  float64 `json:"Pg"`
+	Bc  float64 `json:"Bc"`
+	Bg  float64 `json:"Bg"`
+	Kwh float64 `json:"kwh"`
+	// Totals of all codes including the ones above, keyed like StatsRecords
+	Codes map[string]float64 `json:"-"`
+}
+
+func (t *StatsTotals) UnmarshalJSON(data []byte) error {
+	totals := StatsTotals{Codes: map[string]float64{}}
+
+	// VRM returns an empty array instead of an object if there is no data at all
+	if trimmed := bytes.TrimSpace(data); bytes.Equal(trimmed, []byte("[]")) || bytes.Equal(trimmed, []byte("null")) {
+		*t = totals
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for code, value := range raw {
+		value = bytes.TrimSpace(value)
+		if len(value) == 0 || value[0] != '{' {
+			if err := totals.add(code, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var instances map[string]json.RawMessage
+		if err := json.Unmarshal(value, &instances); err != nil {
+			return fmt.Errorf("could not decode total %s: %w", code, err)
+		}
+		for instance, value := range instances {
+			if err := totals.add(code+":"+instance, bytes.TrimSpace(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	totals.Pb = totals.Codes["Pb"]
+	totals.Pc = totals.Codes["Pc"]
+	totals.Gb = totals.Codes["Gb"]
+	totals.Gc = totals.Codes["Gc"]
+	totals.Pg = totals.Codes["Pg"]
+	totals.Bc = totals.Codes["Bc"]
+	totals.Bg = totals.Codes["Bg"]
+	totals.Kwh = totals.Codes["kwh"]
+
+	*t = totals
+	return nil
+}
+
+// add decodes a single total, skipping codes without data which are reported as false.
+func (t *StatsTotals) add(key string, value json.RawMessage) error {
+	if len(value) == 0 || value[0] == 'f' || value[0] == 'n' {
+		return nil
+	}
+
+	var total float64
+	if err := json.Unmarshal(value, &total); err != nil {
+		return fmt.Errorf("could not decode total %s: %w", key, err)
+	}
+	t.Codes[key] = total
+	return nil
+}
+
+// Instance returns the total of a code for a device instance, as requested with show_instance.
+func (t *StatsTotals) Instance(code string, instance int) float64 {
+	return t.Codes[code+":"+strconv.Itoa(instance)]
+}
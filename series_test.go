@@ -0,0 +1,88 @@
+package vrm_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestStatsRecords(t *testing.T) {
+	x := []byte(`{
+		"bs": [[1441066200000, 50.5, 48, 53], [1441067100000, 52, 51, 53.5]],
+		"Pg": false,
+		"bv": {"0": [[1441066200000, 12.5]], "1": [[1441066200000, 24.8]]},
+		"kwh": [[1441066200000, 1.5], [1441067100000, null], [1441152600000, 2.5]]
+	}`)
+	records := vrm.StatsRecords{}
+	if !assert.NoError(t, json.Unmarshal(x, &records)) {
+		return
+	}
+
+	assert.Len(t, records, 5)
+	assert.Empty(t, records["Pg"])
+	assert.Equal(t, 24.8, records.Instance("bv", 1)[0].Value)
+
+	soc := records["bs"]
+	if assert.Len(t, soc, 2) {
+		assert.Equal(t, time.Unix(1441066200, 0), soc[0].Time)
+		assert.Equal(t, 50.5, soc[0].Value)
+		assert.Equal(t, float64(48), soc[0].Min)
+		assert.Equal(t, float64(53), soc[0].Max)
+	}
+
+	kwh := records["kwh"]
+	assert.True(t, math.IsNaN(kwh[1].Value))
+	assert.Equal(t, 4.0, kwh.Sum())
+
+	daily := kwh.Resample(time.Hour*24, vrm.AggregateSum)
+	if assert.Len(t, daily, 2) {
+		assert.Equal(t, 1.5, daily[0].Value)
+		assert.Equal(t, 2.5, daily[1].Value)
+	}
+
+	hourly := soc.Resample(time.Hour, vrm.AggregateMean)
+	if assert.Len(t, hourly, 1) {
+		assert.Equal(t, 51.25, hourly[0].Value)
+		assert.Equal(t, float64(48), hourly[0].Min)
+		assert.Equal(t, 53.5, hourly[0].Max)
+	}
+
+	times, values := vrm.Align(soc, kwh)
+	if assert.Len(t, times, 3) {
+		assert.Equal(t, []float64{50.5, 1.5}, values[0])
+		assert.True(t, math.IsNaN(values[2][0]))
+		assert.Equal(t, 2.5, values[2][1])
+	}
+}
+
+func TestEmptyStatsRecords(t *testing.T) {
+	stats := vrm.StatsResponse{}
+	if assert.NoError(t, json.Unmarshal([]byte(`{"success": true, "records": [], "totals": {}}`), &stats)) {
+		assert.Empty(t, stats.Records)
+	}
+}
+
+func TestStatsTotals(t *testing.T) {
+	stats := vrm.StatsResponse{}
+	err := json.Unmarshal([]byte(`{
+		"success": true,
+		"records": {"Pc": false, "bv": false},
+		"totals": {"Pc": 1.5, "Pb": false, "bs": null, "bv": {"0": 12.5, "1": false}, "kwh": 3}
+	}`), &stats)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1.5, stats.Totals.Pc)
+		assert.Equal(t, 0.0, stats.Totals.Pb)
+		assert.Equal(t, 3.0, stats.Totals.Kwh)
+		assert.Equal(t, map[string]float64{"Pc": 1.5, "bv:0": 12.5, "kwh": 3}, stats.Totals.Codes)
+		assert.Equal(t, 12.5, stats.Totals.Instance("bv", 0))
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"success": true, "records": [], "totals": []}`), &stats))
+	assert.Empty(t, stats.Totals.Codes)
+	assert.Equal(t, 0.0, stats.Totals.Kwh)
+}
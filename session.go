@@ -2,60 +2,205 @@ package vrm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
-	"github.com/pkg/errors"
-
-	_ "github.com/rs/zerolog"
+	"github.com/rs/zerolog"
 )
 
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+const (
+	// Authorization scheme used for tokens obtained by logging in
+	bearerAuthScheme string = "Bearer"
+	// Authorization scheme used for personal access tokens
+	accessTokenAuthScheme string = "Token"
+)
+
+const defaultTimeout = time.Second * 10
+
 type vrmSession struct {
-	token  string
-	Client HTTPClient
-	UserID int
+	// Guards token, authScheme and userID which change on re-login
+	mu         sync.Mutex
+	token      string
+	authScheme string
+	userID     int
+	// Serializes re-logins of concurrent requests
+	reloginMu sync.Mutex
+	baseURL   string
+	userAgent string
+	timeout   time.Duration
+	// Retry policy for idempotent requests; nil disables retries
+	retryPolicy *RetryPolicy
+	// Limiter throttling all requests; nil disables throttling
+	limiter RateLimiter
+	// Store persisting the token; nil keeps it in memory only
+	tokenStore TokenStore
+	// Provider of credentials for logging in again once the token expired; nil disables re-login
+	credentials CredentialProvider
+	// Hooks called around every request
+	hooks []Hooks
+	// Logger of every request; nil disables logging
+	logger *zerolog.Logger
+	// Options of the login request, applied by Login only
+	loginOptions []LoginOption
+	Client       HTTPClient
 }
 
-func newVRMSession() *vrmSession {
-	return &vrmSession{
-		Client: &http.Client{
-			Timeout: time.Second * 10,
-		},
+func newVRMSession(opts ...Option) *vrmSession {
+	s := &vrmSession{
+		authScheme: bearerAuthScheme,
+		baseURL:    baseURL,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.Client == nil {
+		timeout := s.timeout
+		if timeout == 0 {
+			timeout = defaultTimeout
+		}
+		s.Client = &http.Client{
+			Timeout: timeout,
+		}
+	} else if c, ok := s.Client.(*http.Client); ok && s.timeout > 0 {
+		// Don't modify the caller's client
+		client := *c
+		client.Timeout = s.timeout
+		s.Client = &client
 	}
+
+	return s
+}
+
+func (s *vrmSession) authorization() (token, scheme string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token, s.authScheme
 }
 
-func (s *vrmSession) request(method, url string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, body)
+// UserID returns the ID of the user the session belongs to.
+func (s *vrmSession) UserID() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.userID
+}
+
+// setToken updates the session's token and persists it if the session has a token store.
+func (s *vrmSession) setToken(token, scheme string, userID int) error {
+	s.mu.Lock()
+	s.token = token
+	s.authScheme = scheme
+	s.userID = userID
+	s.mu.Unlock()
+
+	if s.tokenStore == nil {
+		return nil
+	}
+	if err := s.tokenStore.Save(s.StoredToken()); err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
+	}
+	return nil
+}
+
+// request sends an authorized request. If the token has expired and the session has a credential
+// provider, it logs in again and repeats the request once.
+func (s *vrmSession) request(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	token, scheme := s.authorization()
+	res, err := s.send(ctx, method, url, body, token, scheme)
+	if err == nil || len(token) == 0 || s.credentials == nil || !IsUnauthorized(err) {
+		return res, err
+	}
+
+	if err := s.relogin(ctx, token); err != nil {
+		return nil, fmt.Errorf("re-login failed: %w", err)
+	}
+	token, scheme = s.authorization()
+	return s.send(ctx, method, url, body, token, scheme)
+}
+
+// relogin replaces the expired token unless a concurrent request already did so.
+func (s *vrmSession) relogin(ctx context.Context, expired string) error {
+	s.reloginMu.Lock()
+	defer s.reloginMu.Unlock()
+
+	if token, _ := s.authorization(); token != expired {
+		return nil
+	}
+
+	req, err := s.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	return s.login(ctx, req)
+}
+
+func (s *vrmSession) send(ctx context.Context, method, url string, body []byte, token, scheme string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	if len(s.token) > 0 {
-		req.Header.Add("X-Authorization", "Bearer "+s.token)
+	if len(s.userAgent) > 0 {
+		req.Header.Set("User-Agent", s.userAgent)
+	}
+	if len(token) > 0 {
+		req.Header.Add("X-Authorization", scheme+" "+token)
 	}
 
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limiter: %w", err)
+		}
+	}
+
+	observed, req := s.observe(ctx, req)
 	res, err := s.Client.Do(req)
 	if err != nil {
+		observed.failed(err)
+		observed.done(0)
 		return nil, fmt.Errorf("failed to execute request at %s: %w", url, err)
 	}
+	observed.received(res)
+	res.Body = observed.body(res.Body)
 
 	if !(res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices) {
-		return nil, fmt.Errorf("http error: %d", res.StatusCode)
+		defer res.Body.Close()
+		apiErr := newAPIError(res)
+		observed.failed(apiErr)
+		return nil, apiErr
 	}
 
 	return res, nil
 }
 
-func (s *vrmSession) getAndLoad(url string, resData interface{}) error {
-	res, err := s.request(http.MethodGet, url, nil)
+func (s *vrmSession) getAndLoad(ctx context.Context, url string, resData interface{}) error {
+	res, err := s.idempotentRequest(ctx, url)
+	return load(res, err, resData)
+}
+
+// getOnceAndLoad is like getAndLoad but never retries the request, for GET requests changing state
+// like revoking an access token.
+func (s *vrmSession) getOnceAndLoad(ctx context.Context, url string, resData interface{}) error {
+	res, err := s.request(ctx, http.MethodGet, url, nil)
+	return load(res, err, resData)
+}
+
+// load decodes the response of a GET request.
+func load(res *http.Response, err error, resData interface{}) error {
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -68,18 +213,25 @@ func (s *vrmSession) getAndLoad(url string, resData interface{}) error {
 	return nil
 }
 
-func (s *vrmSession) postAndLoad(reqData interface{}, url string, resData interface{}) error {
+func (s *vrmSession) postAndLoad(ctx context.Context, reqData interface{}, url string, resData interface{}) error {
+	return s.sendAndLoad(ctx, http.MethodPost, reqData, url, resData)
+}
+
+func (s *vrmSession) sendAndLoad(ctx context.Context, method string, reqData interface{}, url string, resData interface{}) error {
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(reqData); err != nil {
 		return err
 	}
 
-	res, err := s.request(http.MethodPost, url, buf)
+	res, err := s.request(ctx, method, url, buf.Bytes())
 	if err != nil {
-		return errors.Wrapf(err, "failed to create POST request")
+		return fmt.Errorf("failed to create %s request: %w", method, err)
 	}
 	defer res.Body.Close()
 
+	if resData == nil {
+		return nil
+	}
 	if err := json.NewDecoder(res.Body).Decode(resData); err != nil {
 		return err
 	}
@@ -87,80 +239,184 @@ func (s *vrmSession) postAndLoad(reqData interface{}, url string, resData interf
 	return nil
 }
 
+// LoginOption configures the login request sent by Login, see WithLoginOptions.
 type LoginOption func(*LoginRequest)
 
+// WithSMSToken sets the two-factor token sent along with the credentials on login.
 func WithSMSToken(smsToken string) LoginOption {
 	return func(r *LoginRequest) {
 		r.SMSToken = smsToken
 	}
 }
 
+// WithLoginOptions applies the given options to the login request sent by Login. They are not used for
+// logging in again with a credential provider.
+func WithLoginOptions(opts ...LoginOption) Option {
+	return func(s *vrmSession) {
+		s.loginOptions = append(s.loginOptions, opts...)
+	}
+}
+
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	SMSToken string `json:"sms_token,omitempty"`
 }
 
-func Login(username, password string, opts ...LoginOption) (*vrmSession, error) {
-	url, err := formatURL(loginURL, URLParams{}, nil)
-	if err != nil {
-		return nil, err
-	}
+// Login logs in with the given credentials and creates a session configured by the given options.
+func Login(username, password string, opts ...Option) (Session, error) {
+	return LoginContext(context.Background(), username, password, opts...)
+}
 
+// LoginContext is like Login but carries a context for the login request.
+func LoginContext(ctx context.Context, username, password string, opts ...Option) (Session, error) {
+	s := newVRMSession(opts...)
 	req := &LoginRequest{
 		Username: username,
 		Password: password,
 	}
-	for _, opt := range opts {
+	for _, opt := range s.loginOptions {
 		opt(req)
 	}
+	// Tokens like the SMS token are valid for a single login only
+	s.loginOptions = nil
 
-	response := struct {
-		Token string `json:"token"`
-		UserID int `json:"idUser"`
-	}{}
+	if err := s.login(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// LoginWithRequest logs in with the given credentials and creates a session configured by the given options.
+func LoginWithRequest(req *LoginRequest, opts ...Option) (Session, error) {
+	return LoginWithRequestContext(context.Background(), req, opts...)
+}
 
-	s := newVRMSession()
-	if err := s.postAndLoad(req, url, &response); err != nil {
+// LoginWithRequestContext is like LoginWithRequest but carries a context for the login request.
+func LoginWithRequestContext(ctx context.Context, req *LoginRequest, opts ...Option) (Session, error) {
+	s := newVRMSession(opts...)
+	if err := s.login(ctx, req); err != nil {
 		return nil, err
 	}
-	s.token = response.Token
-	s.UserID = response.UserID
 
 	return s, nil
 }
 
-func LoginAsDemo() (*vrmSession, error) {
-	url, err := formatURL(loginAsDemoURL, URLParams{}, nil)
+// login obtains a new token without sending the current one.
+func (s *vrmSession) login(ctx context.Context, req *LoginRequest) error {
+	url, err := s.formatURL(loginURL, URLParams{}, nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.send(ctx, http.MethodPost, url, body, "", "")
+	if err != nil {
+		return fmt.Errorf("failed to create POST request: %w", err)
+	}
+	defer res.Body.Close()
+
+	response := struct {
+		Token  string `json:"token"`
+		UserID int    `json:"idUser"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return err
+	}
+
+	return s.setToken(response.Token, bearerAuthScheme, response.UserID)
+}
+
+func LoginAsDemo(opts ...Option) (Session, error) {
+	return LoginAsDemoContext(context.Background(), opts...)
+}
+
+// LoginAsDemoContext is like LoginAsDemo but carries a context for the login request.
+func LoginAsDemoContext(ctx context.Context, opts ...Option) (Session, error) {
+	s := newVRMSession(opts...)
+	url, err := s.formatURL(loginAsDemoURL, URLParams{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	response := struct {
-		Token string `json:"token"`
+		Token  string `json:"token"`
 		UserID string `json:"idUser"`
 	}{}
 
-	s := newVRMSession()
-	if err := s.getAndLoad(url, &response); err != nil {
+	if err := s.getOnceAndLoad(ctx, url, &response); err != nil {
+		return nil, err
+	}
+
+	if err := s.setToken(response.Token, bearerAuthScheme, DemoUserID); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NewSessionWithAccessToken creates a session authenticating with a personal access token instead of a password.
+// The token is validated by requesting the user it belongs to. If userID is 0 the token owner's ID is used.
+func NewSessionWithAccessToken(userID int, token string, opts ...Option) (Session, error) {
+	return NewSessionWithAccessTokenContext(context.Background(), userID, token, opts...)
+}
+
+// NewSessionWithAccessTokenContext is like NewSessionWithAccessToken but carries a context for validating the token.
+func NewSessionWithAccessTokenContext(ctx context.Context, userID int, token string, opts ...Option) (Session, error) {
+	s := newVRMSession(opts...)
+	url, err := s.formatURL(userMeURL, URLParams{}, nil)
+	if err != nil {
 		return nil, err
 	}
 
-	s.token = response.Token
-	s.UserID = DemoUserID
+	response := struct {
+		Success bool `json:"success"`
+		User    struct {
+			ID int `json:"id"`
+		} `json:"user"`
+	}{}
+
+	s.token = token
+	s.authScheme = accessTokenAuthScheme
+	if err := s.getAndLoad(ctx, url, &response); err != nil {
+		return nil, fmt.Errorf("failed to validate access token: %w", err)
+	}
+
+	if userID == 0 {
+		userID = response.User.ID
+	} else if response.User.ID != 0 && response.User.ID != userID {
+		return nil, fmt.Errorf("access token belongs to user %d, not %d", response.User.ID, userID)
+	}
+
+	if err := s.setToken(token, accessTokenAuthScheme, userID); err != nil {
+		return nil, err
+	}
 
 	return s, nil
 }
 
 func (s *vrmSession) Logout() error {
-	url, err := formatURL(logoutURL, URLParams{}, nil)
+	return s.LogoutContext(context.Background())
+}
+
+// LogoutContext is like Logout but carries a context.
+func (s *vrmSession) LogoutContext(ctx context.Context) error {
+	url, err := s.formatURL(logoutURL, URLParams{}, nil)
 	if err != nil {
 		return err
 	}
 
-	if err := s.postAndLoad(struct{}{}, url, nil); err != nil {
+	if err := s.postAndLoad(ctx, struct{}{}, url, nil); err != nil {
 		return err
 	}
 
+	if s.tokenStore != nil {
+		return s.tokenStore.Clear()
+	}
 	return nil
 }
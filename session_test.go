@@ -1,16 +1,130 @@
 package vrm_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
 	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
 
-	"victron/vrm"
+	vrm "github.com/christianschmizz/go-victron"
 )
 
 func ExampleLogin() {
-	session, err := vrm.Login("username", "password", vrm.WithSMSToken("123456"))
+	session, err := vrm.Login("username", "password", vrm.WithLoginOptions(vrm.WithSMSToken("123456")))
 	if err != nil {
 		log.Error().Err(err).Msg("login failed")
 	}
 
 	_ = session.Logout()
 }
+
+func ExampleNewSessionWithAccessToken() {
+	session, err := vrm.NewSessionWithAccessToken(0, "personal-access-token")
+	if err != nil {
+		log.Error().Err(err).Msg("access token rejected")
+		return
+	}
+
+	_, _ = session.Installations(session.UserID())
+}
+
+func TestNewSessionWithAccessToken(t *testing.T) {
+	var paths, authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		authorizations = append(authorizations, r.Header.Get("X-Authorization"))
+		switch {
+		case r.Header.Get("X-Authorization") != "Token personal-access-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"success": false, "errors": "Authentication failed", "error_code": "invalid_token"}`)
+		case r.URL.Path == "/users/me":
+			fmt.Fprint(w, `{"success": true, "user": {"id": 42, "name": "Jane"}}`)
+		default:
+			fmt.Fprint(w, `{"success": true, "records": []}`)
+		}
+	}))
+	defer server.Close()
+
+	session, err := vrm.NewSessionWithAccessToken(0, "personal-access-token", vrm.WithBaseURL(server.URL))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 42, session.UserID())
+		_, err = session.Installations(session.UserID())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"/users/me", "/users/42/installations"}, paths)
+	assert.Equal(t, []string{"Token personal-access-token", "Token personal-access-token"}, authorizations)
+
+	_, err = vrm.NewSessionWithAccessToken(0, "revoked", vrm.WithBaseURL(server.URL))
+	assert.True(t, vrm.IsUnauthorized(err))
+}
+
+func TestContextCancel(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+	start := time.Now()
+	_, err = session.SystemOverviewContext(ctx, 1234)
+	assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestLoginOptions(t *testing.T) {
+	var requests []vrm.LoginRequest
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/auth/login", r.URL.Path)
+		req := vrm.LoginRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		fmt.Fprint(w, `{"token": "token", "idUser": 42}`)
+	}))
+	defer server.Close()
+
+	session, err := vrm.Login("jane", "secret", vrm.WithBaseURL(server.URL), vrm.WithUserAgent("test/1.0"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 42, session.UserID())
+	}
+
+	withSMSToken := func(r *vrm.LoginRequest) {
+		r.SMSToken = "654321"
+	}
+	_, err = vrm.Login("jane", "secret", vrm.WithBaseURL(server.URL),
+		vrm.WithLoginOptions(vrm.WithSMSToken("123456")), vrm.WithLoginOptions(withSMSToken))
+	assert.NoError(t, err)
+
+	_, err = vrm.LoginWithRequest(&vrm.LoginRequest{Username: "john", Password: "secret"}, vrm.WithBaseURL(server.URL))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []vrm.LoginRequest{
+		{Username: "jane", Password: "secret"},
+		{Username: "jane", Password: "secret", SMSToken: "654321"},
+		{Username: "john", Password: "secret"},
+	}, requests)
+	assert.Equal(t, "test/1.0", userAgents[0])
+}
@@ -0,0 +1,119 @@
+package vrm
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+type StatsInterval string
+
+const (
+	Interval15Mins StatsInterval = "15mins"
+	IntervalHours  StatsInterval = "hours"
+	Interval2Hours StatsInterval = "2hours"
+	IntervalDays   StatsInterval = "days"
+	IntervalWeeks  StatsInterval = "weeks"
+	IntervalMonths StatsInterval = "months"
+	IntervalYears  StatsInterval = "years"
+)
+
+type StatsType string
+
+const (
+	StatsTypeVenus       StatsType = "venus"
+	StatsTypeLiveFeed    StatsType = "live_feed"
+	StatsTypeConsumption StatsType = "consumption"
+	StatsTypeKwh         StatsType = "kwh"
+	StatsTypeSolarYield  StatsType = "solar_yield"
+	StatsTypeForecast    StatsType = "forecast"
+	// Custom stats require the attribute codes to be given
+	StatsTypeCustom StatsType = "custom"
+)
+
+// StatsQuery selects the period, interval and kind of stats to request. Zero values are left to VRM's defaults.
+type StatsQuery struct {
+	Start          time.Time
+	End            time.Time
+	Interval       StatsInterval
+	Type           StatsType
+	AttributeCodes []string
+	ShowInstance   bool
+}
+
+func (q StatsQuery) values() (interface{}, error) {
+	if q.Type == StatsTypeCustom && len(q.AttributeCodes) == 0 {
+		return nil, errors.New("custom stats require at least one attribute code")
+	}
+
+	v := struct {
+		Start          int64         `url:"start,omitempty"`
+		End            int64         `url:"end,omitempty"`
+		Interval       StatsInterval `url:"interval,omitempty"`
+		Type           StatsType     `url:"type,omitempty"`
+		AttributeCodes []string      `url:"attributeCodes[],omitempty"`
+		ShowInstance   bool          `url:"show_instance,int,omitempty"`
+	}{
+		Interval:       q.Interval,
+		Type:           q.Type,
+		AttributeCodes: q.AttributeCodes,
+		ShowInstance:   q.ShowInstance,
+	}
+	if !q.Start.IsZero() {
+		v.Start = q.Start.Unix()
+	}
+	if !q.End.IsZero() {
+		v.End = q.End.Unix()
+	}
+	return v, nil
+}
+
+type StatsResponse struct {
+	Success bool         `json:"success"`
+	Records StatsRecords `json:"records"`
+	Totals  StatsTotals  `json:"totals"`
+}
+
+// Request the so-called energy readings for a given installation/site of the last 12 months in 15 minute intervals.
+func (s *vrmSession) Stats(siteID int) (*StatsResponse, error) {
+	return s.StatsContext(context.Background(), siteID)
+}
+
+// StatsContext is like Stats but carries a context.
+func (s *vrmSession) StatsContext(ctx context.Context, siteID int) (*StatsResponse, error) {
+	now := time.Now()
+	return s.StatsWithQueryContext(ctx, siteID, StatsQuery{
+		Start:    now.AddDate(0, -12, 0),
+		End:      now,
+		Interval: Interval15Mins,
+		Type:     StatsTypeKwh,
+	})
+}
+
+// Request stats for a given installation/site as selected by the query.
+func (s *vrmSession) StatsWithQuery(siteID int, query StatsQuery) (*StatsResponse, error) {
+	return s.StatsWithQueryContext(context.Background(), siteID, query)
+}
+
+// StatsWithQueryContext is like StatsWithQuery but carries a context.
+func (s *vrmSession) StatsWithQueryContext(ctx context.Context, siteID int, query StatsQuery) (*StatsResponse, error) {
+	values, err := query.values()
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.formatURL(statsURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, values)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := StatsResponse{}
+	if err := s.getAndLoad(ctx, url, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,48 @@
+package vrm_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestStatsQuery(t *testing.T) {
+	var query url.Values
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/installations/1234/stats", r.URL.Path)
+		query = r.URL.Query()
+		fmt.Fprint(w, `{"success": true, "records": {}, "totals": {}}`)
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	_, err = session.StatsWithQuery(1234, vrm.StatsQuery{
+		Start:          start,
+		End:            start.AddDate(0, 1, 0),
+		Interval:       vrm.IntervalDays,
+		Type:           vrm.StatsTypeCustom,
+		AttributeCodes: []string{"bs", "bv"},
+		ShowInstance:   true,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1598918400", query.Get("start"))
+		assert.Equal(t, "1601510400", query.Get("end"))
+		assert.Equal(t, "days", query.Get("interval"))
+		assert.Equal(t, "custom", query.Get("type"))
+		assert.Equal(t, []string{"bs", "bv"}, query["attributeCodes[]"])
+		assert.Equal(t, "1", query.Get("show_instance"))
+	}
+
+	_, err = session.StatsWithQuery(1234, vrm.StatsQuery{Type: vrm.StatsTypeCustom})
+	assert.Error(t, err, "custom stats without attribute codes")
+}
@@ -0,0 +1,99 @@
+package vrm
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+type TagsResponse struct {
+	Success bool  `json:"success"`
+	Tags    []Tag `json:"tags"`
+}
+
+// List the tags of an installation
+func (s *vrmSession) ListTags(siteID int) (*TagsResponse, error) {
+	return s.ListTagsContext(context.Background(), siteID)
+}
+
+// ListTagsContext is like ListTags but carries a context.
+func (s *vrmSession) ListTagsContext(ctx context.Context, siteID int) (*TagsResponse, error) {
+	url, err := s.formatURL(tagsURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := TagsResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+type tagRequest struct {
+	Tag string `json:"tag"`
+}
+
+type TagResponse struct {
+	Success bool `json:"success"`
+}
+
+// Tag an installation with the given name
+func (s *vrmSession) AddTag(siteID int, tag string) (*TagResponse, error) {
+	return s.AddTagContext(context.Background(), siteID, tag)
+}
+
+// AddTagContext is like AddTag but carries a context.
+func (s *vrmSession) AddTagContext(ctx context.Context, siteID int, tag string) (*TagResponse, error) {
+	return s.changeTag(ctx, http.MethodPut, siteID, tag)
+}
+
+// Remove the tag with the given name from an installation
+func (s *vrmSession) RemoveTag(siteID int, tag string) (*TagResponse, error) {
+	return s.RemoveTagContext(context.Background(), siteID, tag)
+}
+
+// RemoveTagContext is like RemoveTag but carries a context.
+func (s *vrmSession) RemoveTagContext(ctx context.Context, siteID int, tag string) (*TagResponse, error) {
+	return s.changeTag(ctx, http.MethodDelete, siteID, tag)
+}
+
+func (s *vrmSession) changeTag(ctx context.Context, method string, siteID int, tag string) (*TagResponse, error) {
+	url, err := s.formatURL(tagsURL, URLParams{
+		"siteID": strconv.Itoa(siteID),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := TagResponse{}
+	if err := s.sendAndLoad(ctx, method, tagRequest{Tag: tag}, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// Retrieve the installations of the session's user which are tagged with the given name
+func (s *vrmSession) InstallationsByTag(tag string) ([]Installation, error) {
+	return s.InstallationsByTagContext(context.Background(), tag)
+}
+
+// InstallationsByTagContext is like InstallationsByTag but carries a context.
+func (s *vrmSession) InstallationsByTagContext(ctx context.Context, tag string) ([]Installation, error) {
+	installs, err := s.InstallationsContext(ctx, s.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	var tagged []Installation
+	for _, install := range installs.Records {
+		if install.HasTag(tag) {
+			tagged = append(tagged, install)
+		}
+	}
+	return tagged, nil
+}
@@ -0,0 +1,59 @@
+package vrm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestTags(t *testing.T) {
+	var changes []string
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/22/installations":
+			fmt.Fprint(w, `{"success": true, "records": [
+				{"idSite": 1, "name": "Boat", "tags": [{"idTag": 7, "name": "customer-x"}]},
+				{"idSite": 2, "name": "House", "tags": [{"idTag": 8, "name": "customer-y"}]},
+				{"idSite": 3, "name": "Barn", "tags": [{"idTag": 8, "name": "customer-y"}, {"idTag": 7, "name": "customer-x"}]}
+			]}`)
+		case "/installations/1/tags":
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `{"success": true, "tags": [{"idTag": 7, "name": "customer-x", "automatic": false}]}`)
+				return
+			}
+			body := struct {
+				Tag string `json:"tag"`
+			}{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			changes = append(changes, r.Method+" "+body.Tag)
+			fmt.Fprint(w, `{"success": true}`)
+		}
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tags, err := session.ListTags(1)
+	if assert.NoError(t, err) && assert.Len(t, tags.Tags, 1) {
+		assert.Equal(t, "customer-x", tags.Tags[0].Name)
+	}
+
+	_, err = session.AddTag(1, "customer-z")
+	assert.NoError(t, err)
+	_, err = session.RemoveTag(1, "customer-x")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PUT customer-z", "DELETE customer-x"}, changes)
+
+	tagged, err := session.InstallationsByTag("customer-x")
+	if assert.NoError(t, err) && assert.Len(t, tagged, 2) {
+		assert.Equal(t, 1, tagged[0].SiteID)
+		assert.Equal(t, 3, tagged[1].SiteID)
+	}
+}
@@ -0,0 +1,171 @@
+package vrm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoStoredToken is returned by a TokenStore which holds no token.
+var ErrNoStoredToken = errors.New("no stored token")
+
+// StoredToken is the serialisable state of a session.
+type StoredToken struct {
+	Token      string    `json:"token"`
+	AuthScheme string    `json:"authScheme"`
+	UserID     int       `json:"idUser"`
+	SavedAt    time.Time `json:"savedAt"`
+}
+
+// TokenStore persists the token of a session across process runs.
+type TokenStore interface {
+	// Load returns the stored token or ErrNoStoredToken
+	Load() (StoredToken, error)
+	Save(token StoredToken) error
+	Clear() error
+}
+
+// CredentialProvider returns the credentials to log in again once a session's token expired,
+// e.g. by reading a secret or prompting for an SMS token.
+type CredentialProvider func(ctx context.Context) (*LoginRequest, error)
+
+// WithTokenStore persists the session's token whenever it changes.
+func WithTokenStore(store TokenStore) Option {
+	return func(s *vrmSession) {
+		s.tokenStore = store
+	}
+}
+
+// WithCredentialProvider makes the session log in again with the provided credentials when VRM
+// rejects its token as unauthorized.
+func WithCredentialProvider(provider CredentialProvider) Option {
+	return func(s *vrmSession) {
+		s.credentials = provider
+	}
+}
+
+// StoredToken returns the current state of the session for persisting it.
+func (s *vrmSession) StoredToken() StoredToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StoredToken{
+		Token:      s.token,
+		AuthScheme: s.authScheme,
+		UserID:     s.userID,
+		SavedAt:    time.Now(),
+	}
+}
+
+// ResumeSession restores a session from the token store. If the store holds no token, the session
+// logs in using the credential provider given by WithCredentialProvider. The token is not validated;
+// an expired token is replaced on the first unauthorized response if a credential provider is given.
+func ResumeSession(store TokenStore, opts ...Option) (Session, error) {
+	return ResumeSessionContext(context.Background(), store, opts...)
+}
+
+// ResumeSessionContext is like ResumeSession but carries a context for a possible login.
+func ResumeSessionContext(ctx context.Context, store TokenStore, opts ...Option) (Session, error) {
+	s := newVRMSession(append(opts, WithTokenStore(store))...)
+
+	stored, err := store.Load()
+	if errors.Is(err, ErrNoStoredToken) && s.credentials != nil {
+		req, err := s.credentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.login(ctx, req); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.token = stored.Token
+	s.authScheme = stored.AuthScheme
+	if len(s.authScheme) == 0 {
+		s.authScheme = bearerAuthScheme
+	}
+	s.userID = stored.UserID
+
+	return s, nil
+}
+
+// MemoryTokenStore keeps the token in memory, e.g. to share it between sessions of one process.
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token *StoredToken
+}
+
+func (m *MemoryTokenStore) Load() (StoredToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token == nil {
+		return StoredToken{}, ErrNoStoredToken
+	}
+	return *m.token, nil
+}
+
+func (m *MemoryTokenStore) Save(token StoredToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.token = &token
+	return nil
+}
+
+func (m *MemoryTokenStore) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.token = nil
+	return nil
+}
+
+// FileTokenStore keeps the token as JSON file readable by the owner only.
+type FileTokenStore struct {
+	Path string
+}
+
+func (f *FileTokenStore) Load() (StoredToken, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return StoredToken{}, ErrNoStoredToken
+	}
+	if err != nil {
+		return StoredToken{}, err
+	}
+
+	token := StoredToken{}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return StoredToken{}, err
+	}
+	if len(token.Token) == 0 {
+		return StoredToken{}, ErrNoStoredToken
+	}
+	return token, nil
+}
+
+func (f *FileTokenStore) Save(token StoredToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	// Replace the file atomically so a crash never leaves a truncated token behind
+	tmp := f.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.Path)
+}
+
+func (f *FileTokenStore) Clear() error {
+	if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package vrm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestRelogin(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/login":
+			assert.Empty(t, r.Header.Get("X-Authorization"))
+			req := vrm.LoginRequest{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "123456", req.SMSToken)
+			logins++
+			fmt.Fprintf(w, `{"token": "token-%d", "idUser": 42}`, logins)
+		case "/users/42/installations":
+			if r.Header.Get("X-Authorization") != "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"success": false, "errors": "Token is expired", "error_code": "invalid_token"}`)
+				return
+			}
+			fmt.Fprint(w, `{"success": true, "records": []}`)
+		}
+	}))
+	defer server.Close()
+
+	store := &vrm.MemoryTokenStore{}
+	assert.NoError(t, store.Save(vrm.StoredToken{Token: "expired", AuthScheme: "Bearer", UserID: 42}))
+
+	session, err := vrm.ResumeSession(store, vrm.WithBaseURL(server.URL), vrm.WithCredentialProvider(
+		func(ctx context.Context) (*vrm.LoginRequest, error) {
+			return &vrm.LoginRequest{Username: "jane", Password: "secret", SMSToken: "123456"}, nil
+		}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 0, logins, "stored token is used without logging in")
+
+	installs, err := session.Installations(session.UserID())
+	if assert.NoError(t, err) {
+		assert.True(t, installs.Success)
+	}
+	assert.Equal(t, 1, logins)
+
+	stored, err := store.Load()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "token-1", stored.Token)
+		assert.Equal(t, 42, stored.UserID)
+	}
+}
+
+func TestConcurrentRelogin(t *testing.T) {
+	var logins int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/login":
+			time.Sleep(time.Millisecond * 5)
+			fmt.Fprintf(w, `{"token": "token-%d", "idUser": 42}`, atomic.AddInt32(&logins, 1))
+		case "/users/42/installations", "/users/42/accesstokens/list":
+			if r.Header.Get("X-Authorization") != "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"success": false, "errors": "Token is expired", "error_code": "invalid_token"}`)
+				return
+			}
+			fmt.Fprint(w, `{"success": true, "records": [], "tokens": []}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store := &vrm.MemoryTokenStore{}
+	assert.NoError(t, store.Save(vrm.StoredToken{Token: "expired", AuthScheme: "Bearer", UserID: 42}))
+
+	session, err := vrm.ResumeSession(store, vrm.WithBaseURL(server.URL), vrm.WithCredentialProvider(
+		func(ctx context.Context) (*vrm.LoginRequest, error) {
+			return &vrm.LoginRequest{Username: "jane", Password: "secret"}, nil
+		}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Requests started during a re-login read the user ID while it is replaced; run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := session.Installations(session.UserID())
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := session.ListAccessTokens()
+			assert.NoError(t, err)
+		}()
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&logins))
+	assert.Equal(t, 42, session.UserID())
+}
+
+func TestResumeSessionWithoutToken(t *testing.T) {
+	_, err := vrm.ResumeSession(&vrm.MemoryTokenStore{})
+	assert.Equal(t, vrm.ErrNoStoredToken, err)
+}
+
+func TestFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vrm")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	store := &vrm.FileTokenStore{Path: filepath.Join(dir, "token.json")}
+	_, err = store.Load()
+	assert.Equal(t, vrm.ErrNoStoredToken, err)
+
+	assert.NoError(t, store.Save(vrm.StoredToken{Token: "abc", AuthScheme: "Token", UserID: 7}))
+	info, err := os.Stat(store.Path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	token, err := store.Load()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "abc", token.Token)
+		assert.Equal(t, "Token", token.AuthScheme)
+		assert.Equal(t, 7, token.UserID)
+	}
+
+	assert.NoError(t, store.Clear())
+	_, err = store.Load()
+	assert.Equal(t, vrm.ErrNoStoredToken, err)
+}
@@ -24,8 +24,8 @@ func MapTemplate(templateText string, replacements map[string]string) (string, e
 	return buf.String(), nil
 }
 
-func formatURL(urlTemplate string, params URLParams, queryParams interface{}) (string, error) {
-	params["baseURL"] = baseURL
+func (s *vrmSession) formatURL(urlTemplate string, params URLParams, queryParams interface{}) (string, error) {
+	params["baseURL"] = s.baseURL
 
 	url, err := MapTemplate(urlTemplate, params)
 	if err != nil {
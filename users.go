@@ -0,0 +1,151 @@
+package vrm
+
+import (
+	"context"
+)
+
+// Number of users per page if not given in the query
+const defaultUsersPageSize = 100
+
+type UserInstallation struct {
+	SiteID      int    `json:"idSite"`
+	Name        string `json:"name"`
+	AccessLevel int    `json:"accessLevel"`
+}
+
+type User struct {
+	UserID        int                `json:"idUser"`
+	Name          string             `json:"name"`
+	Email         string             `json:"email"`
+	Country       string             `json:"country"`
+	AccessLevel   int                `json:"accessLevel"`
+	Installations []UserInstallation `json:"installations"`
+}
+
+type UsersResponse struct {
+	Success bool   `json:"success"`
+	Users   []User `json:"users"`
+	Total   int    `json:"total"`
+}
+
+// UsersQuery selects a page of users, optionally filtered by a search term matching name or email.
+// Pages start at 1.
+type UsersQuery struct {
+	Page   int    `url:"page,omitempty"`
+	Count  int    `url:"count,omitempty"`
+	Search string `url:"search,omitempty"`
+}
+
+// Retrieve the first page of users administrated by the session's user
+func (s *vrmSession) Users() (*UsersResponse, error) {
+	return s.UsersContext(context.Background())
+}
+
+// UsersContext is like Users but carries a context.
+func (s *vrmSession) UsersContext(ctx context.Context) (*UsersResponse, error) {
+	return s.UsersWithQueryContext(ctx, UsersQuery{})
+}
+
+// Retrieve a page of users administrated by the session's user
+func (s *vrmSession) UsersWithQuery(query UsersQuery) (*UsersResponse, error) {
+	return s.UsersWithQueryContext(context.Background(), query)
+}
+
+// UsersWithQueryContext is like UsersWithQuery but carries a context.
+func (s *vrmSession) UsersWithQueryContext(ctx context.Context, query UsersQuery) (*UsersResponse, error) {
+	url, err := s.formatURL(usersURL, URLParams{}, query)
+	if err != nil {
+		return nil, err
+	}
+
+	data := UsersResponse{}
+	if err := s.getAndLoad(ctx, url, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// UserIterator walks all pages of users. Call Next before each User and check Err once Next returns false:
+//
+//	it := session.AllUsers(vrm.UsersQuery{Search: "example.com"})
+//	for it.Next() {
+//		user := it.User()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type UserIterator struct {
+	ctx   context.Context
+	fetch UsersPageFunc
+	query UsersQuery
+	users []User
+	seen  int
+	done  bool
+	err   error
+}
+
+// UsersPageFunc fetches a single page of users as selected by the query.
+type UsersPageFunc func(ctx context.Context, query UsersQuery) (*UsersResponse, error)
+
+// NewUserIterator creates an iterator fetching pages by the given function, e.g. to iterate over users of
+// a Session implementation other than the one returned by Login.
+func NewUserIterator(ctx context.Context, query UsersQuery, fetch UsersPageFunc) *UserIterator {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Count < 1 {
+		query.Count = defaultUsersPageSize
+	}
+	return &UserIterator{ctx: ctx, fetch: fetch, query: query}
+}
+
+// Iterate over all users administrated by the session's user, starting at the query's page
+func (s *vrmSession) AllUsers(query UsersQuery) *UserIterator {
+	return s.AllUsersContext(context.Background(), query)
+}
+
+// AllUsersContext is like AllUsers but carries a context used for fetching all pages.
+func (s *vrmSession) AllUsersContext(ctx context.Context, query UsersQuery) *UserIterator {
+	return NewUserIterator(ctx, query, s.UsersWithQueryContext)
+}
+
+// Next advances to the next user, fetching the next page if necessary.
+func (it *UserIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.users) > 1 {
+		it.users = it.users[1:]
+		return true
+	}
+	if it.done {
+		it.users = nil
+		return false
+	}
+
+	page, err := it.fetch(it.ctx, it.query)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.query.Page++
+	it.seen += len(page.Users)
+
+	// A short page or reaching the reported total marks the last page
+	if len(page.Users) < it.query.Count || (page.Total > 0 && it.seen >= page.Total) {
+		it.done = true
+	}
+	it.users = page.Users
+	return len(it.users) > 0
+}
+
+// User returns the current user.
+func (it *UserIterator) User() User {
+	return it.users[0]
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *UserIterator) Err() error {
+	return it.err
+}
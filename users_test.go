@@ -0,0 +1,50 @@
+package vrm_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestAllUsers(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"success": true, "total": 3, "users": [
+			{"idUser": 1, "name": "Alice", "email": "alice@example.com", "country": "NL", "accessLevel": 1,
+			 "installations": [{"idSite": 1234, "name": "Boat", "accessLevel": 1}]},
+			{"idUser": 2, "name": "Bob", "email": "bob@example.com", "country": "DE", "accessLevel": 2}
+		]}`,
+		"2": `{"success": true, "total": 3, "users": [
+			{"idUser": 3, "name": "Carol", "email": "carol@example.com", "country": "FR", "accessLevel": 2}
+		]}`,
+	}
+	requests := 0
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/admin/users", r.URL.Path)
+		assert.Equal(t, "example.com", r.URL.Query().Get("search"))
+		assert.Equal(t, "2", r.URL.Query().Get("count"))
+		fmt.Fprint(w, pages[r.URL.Query().Get("page")])
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var names []string
+	it := session.AllUsers(vrm.UsersQuery{Count: 2, Search: "example.com"})
+	for it.Next() {
+		user := it.User()
+		names = append(names, user.Name)
+		if user.UserID == 1 && assert.Len(t, user.Installations, 1) {
+			assert.Equal(t, 1234, user.Installations[0].SiteID)
+		}
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"Alice", "Bob", "Carol"}, names)
+	assert.Equal(t, 2, requests)
+}
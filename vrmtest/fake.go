@@ -0,0 +1,1254 @@
+// Package vrmtest provides test doubles for code depending on the VRM API.
+package vrmtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+// Call is a call made to a Fake. Method is the name without the Context suffix.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Site is the data of an installation served by a Fake. Nil fields make the calls reading them fail
+// with a 404 *vrm.APIError, so vrm.IsNotFound holds.
+type Site struct {
+	// Listed for the user given by its UserID. Tags of the installation are managed on this record.
+	Installation vrm.Installation
+	Settings     *vrm.InstallationSettings
+	Users        *vrm.SiteUsersResponse
+
+	SystemOverview *vrm.SystemOverviewResponse
+	Diagnostics    *vrm.DiagnosticsResponse
+	Stats          *vrm.StatsResponse
+	Download       []byte
+	GPSTrack       vrm.GPSTrack
+	// Widget responses by name, e.g. vrm.WidgetBatterySummary
+	Widgets     map[string]*vrm.WidgetResponse
+	AlarmWidget *vrm.AlarmWidgetResponse
+	HoursOfAC   *vrm.HoursOfACResponse
+	Graph       *vrm.GraphResponse
+	AlarmRules  []vrm.AlarmRule
+	Alarms      []vrm.Alarm
+}
+
+// Fake is an in-memory vrm.Session. Its data is set up by filling the exported fields, directly or from
+// fixture files by LoadFixtures, and changed by the write calls like VRM would change it.
+//
+// The fields are guarded by the fake's lock once it is in use; modify them only before handing the fake
+// to the code under test or while no calls are in flight.
+type Fake struct {
+	mu sync.Mutex
+	// User the fake is logged in as, set by NewFake
+	userID int
+	// Last ID handed out for created access tokens and tags, never reused
+	lastID int
+
+	Token string
+
+	// Sites by site ID
+	Sites map[int]*Site
+	// Users listed by the admin users endpoint
+	AdminUsers   []vrm.User
+	AccessTokens []vrm.AccessToken
+
+	// Errors returned instead of a result, keyed by method name without the Context suffix
+	Errors map[string]error
+
+	// Calls made so far, in order, one per method call; iterators record a single call for all pages
+	Calls []Call
+}
+
+var _ vrm.Session = (*Fake)(nil)
+
+// NewFake creates a fake without any sites for the given user.
+func NewFake(userID int) *Fake {
+	return &Fake{
+		userID: userID,
+		Token:  "fake-token",
+		Sites:  map[int]*Site{},
+		Errors: map[string]error{},
+	}
+}
+
+// AddSite adds a site for the installation and returns it for setting up further data. The installation
+// belongs to the fake's user unless its UserID is set.
+func (f *Fake) AddSite(install vrm.Installation) *Site {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if install.UserID == 0 {
+		install.UserID = f.userID
+	}
+	site := &Site{Installation: install, Widgets: map[string]*vrm.WidgetResponse{}}
+	f.Sites[install.SiteID] = site
+	return site
+}
+
+// CallsTo returns the calls made to the given method.
+func (f *Fake) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []Call
+	for _, call := range f.Calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// call records a call and returns the error it fails with, if any. The lock must be held.
+func (f *Fake) call(ctx context.Context, method string, args ...interface{}) error {
+	f.Calls = append(f.Calls, Call{Method: method, Args: args})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.Errors[method]
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &vrm.APIError{
+		StatusCode: http.StatusNotFound,
+		ErrorCode:  "not_found",
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// site returns the site with the given ID. The lock must be held.
+func (f *Fake) site(siteID int) (*Site, error) {
+	site, ok := f.Sites[siteID]
+	if !ok {
+		return nil, notFound("installation %d not found", siteID)
+	}
+	return site, nil
+}
+
+// installations returns the installations of a user ordered by site ID. The lock must be held.
+func (f *Fake) installations(userID int) []vrm.Installation {
+	var installs []vrm.Installation
+	for _, site := range f.Sites {
+		if site.Installation.UserID == userID {
+			installs = append(installs, site.Installation)
+		}
+	}
+	sort.Slice(installs, func(i, j int) bool { return installs[i].SiteID < installs[j].SiteID })
+	return installs
+}
+
+func (f *Fake) Logout() error {
+	return f.LogoutContext(context.Background())
+}
+
+func (f *Fake) LogoutContext(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Logout"); err != nil {
+		return err
+	}
+	f.Token = ""
+	return nil
+}
+
+func (f *Fake) UserID() int {
+	return f.userID
+}
+
+func (f *Fake) StoredToken() vrm.StoredToken {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return vrm.StoredToken{
+		Token:      f.Token,
+		AuthScheme: "Bearer",
+		UserID:     f.userID,
+		SavedAt:    time.Now(),
+	}
+}
+
+func (f *Fake) ListAccessTokens() (*vrm.AccessTokensResponse, error) {
+	return f.ListAccessTokensContext(context.Background())
+}
+
+func (f *Fake) ListAccessTokensContext(ctx context.Context) (*vrm.AccessTokensResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ListAccessTokens"); err != nil {
+		return nil, err
+	}
+	return &vrm.AccessTokensResponse{
+		Success: true,
+		Tokens:  append([]vrm.AccessToken(nil), f.AccessTokens...),
+	}, nil
+}
+
+func (f *Fake) CreateAccessToken(name string, expiry time.Time) (*vrm.CreateAccessTokenResponse, error) {
+	return f.CreateAccessTokenContext(context.Background(), name, expiry)
+}
+
+// nextID returns a new ID above highest and any ID handed out before. The lock must be held.
+func (f *Fake) nextID(highest int) int {
+	if f.lastID < highest {
+		f.lastID = highest
+	}
+	f.lastID++
+	return f.lastID
+}
+
+func (f *Fake) CreateAccessTokenContext(ctx context.Context, name string, expiry time.Time) (*vrm.CreateAccessTokenResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "CreateAccessToken", name, expiry); err != nil {
+		return nil, err
+	}
+
+	highest := 0
+	for _, token := range f.AccessTokens {
+		if n, err := strconv.Atoi(token.ID); err == nil && n > highest {
+			highest = n
+		}
+	}
+	id := strconv.Itoa(f.nextID(highest))
+	token := vrm.AccessToken{
+		ID:        id,
+		Name:      name,
+		CreatedOn: time.Now().Unix(),
+		Scope:     "all",
+	}
+	if !expiry.IsZero() {
+		expires := expiry.Unix()
+		token.Expires = &expires
+	}
+	f.AccessTokens = append(f.AccessTokens, token)
+
+	return &vrm.CreateAccessTokenResponse{
+		Success:       true,
+		Token:         "fake-access-token-" + id,
+		AccessTokenID: id,
+	}, nil
+}
+
+func (f *Fake) RevokeAccessToken(accessTokenID string) (*vrm.RevokeAccessTokenResponse, error) {
+	return f.RevokeAccessTokenContext(context.Background(), accessTokenID)
+}
+
+func (f *Fake) RevokeAccessTokenContext(ctx context.Context, accessTokenID string) (*vrm.RevokeAccessTokenResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "RevokeAccessToken", accessTokenID); err != nil {
+		return nil, err
+	}
+
+	data := vrm.RevokeAccessTokenResponse{Success: true}
+	tokens := f.AccessTokens[:0]
+	for _, token := range f.AccessTokens {
+		if token.ID == accessTokenID {
+			data.Data.Removed++
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	f.AccessTokens = tokens
+	return &data, nil
+}
+
+func (f *Fake) Users() (*vrm.UsersResponse, error) {
+	return f.UsersContext(context.Background())
+}
+
+func (f *Fake) UsersContext(ctx context.Context) (*vrm.UsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Users"); err != nil {
+		return nil, err
+	}
+	return f.users(vrm.UsersQuery{}), nil
+}
+
+func (f *Fake) UsersWithQuery(query vrm.UsersQuery) (*vrm.UsersResponse, error) {
+	return f.UsersWithQueryContext(context.Background(), query)
+}
+
+func (f *Fake) UsersWithQueryContext(ctx context.Context, query vrm.UsersQuery) (*vrm.UsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UsersWithQuery", query); err != nil {
+		return nil, err
+	}
+	return f.users(query), nil
+}
+
+// users returns the admin users matching the query. The lock must be held.
+func (f *Fake) users(query vrm.UsersQuery) *vrm.UsersResponse {
+	var users []vrm.User
+	search := strings.ToLower(query.Search)
+	for _, user := range f.AdminUsers {
+		if strings.Contains(strings.ToLower(user.Name), search) || strings.Contains(strings.ToLower(user.Email), search) {
+			users = append(users, user)
+		}
+	}
+
+	from, to := pageBounds(len(users), query.Page, query.Count)
+	return &vrm.UsersResponse{
+		Success: true,
+		Users:   users[from:to],
+		Total:   len(users),
+	}
+}
+
+func (f *Fake) AllUsers(query vrm.UsersQuery) *vrm.UserIterator {
+	return f.AllUsersContext(context.Background(), query)
+}
+
+// AllUsersContext records a single call for all pages. An error set for AllUsers fails the first page.
+func (f *Fake) AllUsersContext(ctx context.Context, query vrm.UsersQuery) *vrm.UserIterator {
+	f.mu.Lock()
+	err := f.call(ctx, "AllUsers", query)
+	f.mu.Unlock()
+
+	return vrm.NewUserIterator(ctx, query, func(ctx context.Context, query vrm.UsersQuery) (*vrm.UsersResponse, error) {
+		if err != nil {
+			return nil, err
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return f.users(query), nil
+	})
+}
+
+// pageBounds returns the bounds of a page out of n items. Pages start at 1; a count of 0 selects all items.
+func pageBounds(n int, page int, count int) (from, to int) {
+	if page < 1 {
+		page = 1
+	}
+	if count < 1 {
+		count = n
+	}
+	from = (page - 1) * count
+	if from > n {
+		from = n
+	}
+	to = from + count
+	if to > n {
+		to = n
+	}
+	return from, to
+}
+
+func (f *Fake) Installations(userID int) (*vrm.InstallationsResponse, error) {
+	return f.InstallationsContext(context.Background(), userID)
+}
+
+func (f *Fake) InstallationsContext(ctx context.Context, userID int) (*vrm.InstallationsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Installations", userID); err != nil {
+		return nil, err
+	}
+	return &vrm.InstallationsResponse{Success: true, Records: f.installations(userID)}, nil
+}
+
+func (f *Fake) AllInstallations(query vrm.InstallationsQuery) *vrm.InstallationIterator {
+	return f.AllInstallationsContext(context.Background(), query)
+}
+
+// AllInstallationsContext records a single call for all pages. An error set for AllInstallations fails the
+// first page.
+func (f *Fake) AllInstallationsContext(ctx context.Context, query vrm.InstallationsQuery) *vrm.InstallationIterator {
+	if len(query.UserIDs) == 0 {
+		query.UserIDs = []int{f.userID}
+	}
+	f.mu.Lock()
+	err := f.call(ctx, "AllInstallations", query)
+	f.mu.Unlock()
+
+	return vrm.NewInstallationIterator(ctx, query, func(ctx context.Context, userID int, page int, count int, extended bool) ([]vrm.Installation, error) {
+		if err != nil {
+			return nil, err
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		installs := f.installations(userID)
+		from, to := pageBounds(len(installs), page, count)
+		return installs[from:to], nil
+	})
+}
+
+func (f *Fake) Installation(siteID int) (*vrm.Installation, error) {
+	return f.InstallationContext(context.Background(), siteID)
+}
+
+func (f *Fake) InstallationContext(ctx context.Context, siteID int) (*vrm.Installation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Installation", siteID); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	install := site.Installation
+	return &install, nil
+}
+
+func (f *Fake) InstallationSettings(siteID int) (*vrm.InstallationSettingsResponse, error) {
+	return f.InstallationSettingsContext(context.Background(), siteID)
+}
+
+func (f *Fake) InstallationSettingsContext(ctx context.Context, siteID int) (*vrm.InstallationSettingsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "InstallationSettings", siteID); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.Settings == nil {
+		return nil, notFound("no settings for installation %d", siteID)
+	}
+	return &vrm.InstallationSettingsResponse{Success: true, Data: *site.Settings}, nil
+}
+
+func (f *Fake) UpdateInstallationSettings(siteID int, settings vrm.InstallationSettings) (*vrm.InstallationSettingsResponse, error) {
+	return f.UpdateInstallationSettingsContext(context.Background(), siteID, settings)
+}
+
+// UpdateInstallationSettingsContext replaces the site's settings and updates its installation record accordingly.
+func (f *Fake) UpdateInstallationSettingsContext(ctx context.Context, siteID int, settings vrm.InstallationSettings) (*vrm.InstallationSettingsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UpdateInstallationSettings", siteID, settings); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	site.Settings = &settings
+	site.Installation.Name = settings.Name
+	site.Installation.Timezone = settings.Timezone
+	site.Installation.Geofence = settings.Geofence
+	site.Installation.GeofenceEnabled = settings.GeofenceEnabled
+	site.Installation.ReportsEnabled = settings.ReportsEnabled
+	return &vrm.InstallationSettingsResponse{Success: true, Data: settings}, nil
+}
+
+func (f *Fake) SiteUsers(siteID int) (*vrm.SiteUsersResponse, error) {
+	return f.SiteUsersContext(context.Background(), siteID)
+}
+
+func (f *Fake) SiteUsersContext(ctx context.Context, siteID int) (*vrm.SiteUsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SiteUsers", siteID); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.Users == nil {
+		return nil, notFound("no users for installation %d", siteID)
+	}
+	return &vrm.SiteUsersResponse{
+		Success: true,
+		Users:   append([]vrm.SiteUser(nil), site.Users.Users...),
+		Invites: append([]vrm.SiteInvite(nil), site.Users.Invites...),
+	}, nil
+}
+
+// siteUsers returns the users of a site, creating them if necessary. The lock must be held.
+func (f *Fake) siteUsers(siteID int) (*vrm.SiteUsersResponse, error) {
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.Users == nil {
+		site.Users = &vrm.SiteUsersResponse{Success: true}
+	}
+	return site.Users, nil
+}
+
+func (f *Fake) InviteUser(siteID int, invite vrm.SiteInvite) (*vrm.SiteUserResponse, error) {
+	return f.InviteUserContext(context.Background(), siteID, invite)
+}
+
+func (f *Fake) InviteUserContext(ctx context.Context, siteID int, invite vrm.SiteInvite) (*vrm.SiteUserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "InviteUser", siteID, invite); err != nil {
+		return nil, err
+	}
+
+	users, err := f.siteUsers(siteID)
+	if err != nil {
+		return nil, err
+	}
+	users.Invites = append(users.Invites, invite)
+	return &vrm.SiteUserResponse{Success: true}, nil
+}
+
+func (f *Fake) SetUserAccessLevel(siteID int, userID int, accessLevel int) (*vrm.SiteUserResponse, error) {
+	return f.SetUserAccessLevelContext(context.Background(), siteID, userID, accessLevel)
+}
+
+func (f *Fake) SetUserAccessLevelContext(ctx context.Context, siteID int, userID int, accessLevel int) (*vrm.SiteUserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SetUserAccessLevel", siteID, userID, accessLevel); err != nil {
+		return nil, err
+	}
+
+	users, err := f.siteUsers(siteID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users.Users {
+		if users.Users[i].UserID == userID {
+			users.Users[i].AccessLevel = accessLevel
+			return &vrm.SiteUserResponse{Success: true}, nil
+		}
+	}
+	return nil, notFound("user %d has no access to installation %d", userID, siteID)
+}
+
+func (f *Fake) RemoveUser(siteID int, userID int) (*vrm.SiteUserResponse, error) {
+	return f.RemoveUserContext(context.Background(), siteID, userID)
+}
+
+func (f *Fake) RemoveUserContext(ctx context.Context, siteID int, userID int) (*vrm.SiteUserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "RemoveUser", siteID, userID); err != nil {
+		return nil, err
+	}
+
+	users, err := f.siteUsers(siteID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users.Users {
+		if users.Users[i].UserID == userID {
+			users.Users = append(users.Users[:i], users.Users[i+1:]...)
+			return &vrm.SiteUserResponse{Success: true}, nil
+		}
+	}
+	return nil, notFound("user %d has no access to installation %d", userID, siteID)
+}
+
+func (f *Fake) ListTags(siteID int) (*vrm.TagsResponse, error) {
+	return f.ListTagsContext(context.Background(), siteID)
+}
+
+func (f *Fake) ListTagsContext(ctx context.Context, siteID int) (*vrm.TagsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "ListTags", siteID); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	return &vrm.TagsResponse{Success: true, Tags: append([]vrm.Tag(nil), site.Installation.Tags...)}, nil
+}
+
+func (f *Fake) AddTag(siteID int, tag string) (*vrm.TagResponse, error) {
+	return f.AddTagContext(context.Background(), siteID, tag)
+}
+
+func (f *Fake) AddTagContext(ctx context.Context, siteID int, tag string) (*vrm.TagResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "AddTag", siteID, tag); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if install := &site.Installation; !install.HasTag(tag) {
+		highest := 0
+		for _, t := range install.Tags {
+			if t.TagID > highest {
+				highest = t.TagID
+			}
+		}
+		install.Tags = append(install.Tags, vrm.Tag{TagID: f.nextID(highest), Name: tag})
+	}
+	return &vrm.TagResponse{Success: true}, nil
+}
+
+func (f *Fake) RemoveTag(siteID int, tag string) (*vrm.TagResponse, error) {
+	return f.RemoveTagContext(context.Background(), siteID, tag)
+}
+
+func (f *Fake) RemoveTagContext(ctx context.Context, siteID int, tag string) (*vrm.TagResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "RemoveTag", siteID, tag); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	var tags []vrm.Tag
+	for _, t := range site.Installation.Tags {
+		if t.Name != tag {
+			tags = append(tags, t)
+		}
+	}
+	site.Installation.Tags = tags
+	return &vrm.TagResponse{Success: true}, nil
+}
+
+func (f *Fake) InstallationsByTag(tag string) ([]vrm.Installation, error) {
+	return f.InstallationsByTagContext(context.Background(), tag)
+}
+
+func (f *Fake) InstallationsByTagContext(ctx context.Context, tag string) ([]vrm.Installation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "InstallationsByTag", tag); err != nil {
+		return nil, err
+	}
+
+	var tagged []vrm.Installation
+	for _, install := range f.installations(f.userID) {
+		if install.HasTag(tag) {
+			tagged = append(tagged, install)
+		}
+	}
+	return tagged, nil
+}
+
+func (f *Fake) SystemOverview(siteID int) (*vrm.SystemOverviewResponse, error) {
+	return f.SystemOverviewContext(context.Background(), siteID)
+}
+
+func (f *Fake) SystemOverviewContext(ctx context.Context, siteID int) (*vrm.SystemOverviewResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SystemOverview", siteID); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.SystemOverview == nil {
+		return nil, notFound("no system overview for installation %d", siteID)
+	}
+	data := *site.SystemOverview
+	data.Records.Devices = append(data.Records.Devices[:0:0], data.Records.Devices...)
+	return &data, nil
+}
+
+func (f *Fake) Diagnostics(siteID int, count uint16) (*vrm.DiagnosticsResponse, error) {
+	return f.DiagnosticsContext(context.Background(), siteID, count)
+}
+
+// DiagnosticsContext returns up to count of the site's diagnostics records.
+func (f *Fake) DiagnosticsContext(ctx context.Context, siteID int, count uint16) (*vrm.DiagnosticsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Diagnostics", siteID, count); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.Diagnostics == nil {
+		return nil, notFound("no diagnostics for installation %d", siteID)
+	}
+	data := *site.Diagnostics
+	if count > 0 && int(count) < len(data.Records) {
+		data.Records = data.Records[:count]
+	}
+	data.NumRecords = uint(len(data.Records))
+	return &data, nil
+}
+
+func (f *Fake) Stats(siteID int) (*vrm.StatsResponse, error) {
+	return f.StatsContext(context.Background(), siteID)
+}
+
+func (f *Fake) StatsContext(ctx context.Context, siteID int) (*vrm.StatsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Stats", siteID); err != nil {
+		return nil, err
+	}
+	return f.stats(siteID, vrm.StatsQuery{})
+}
+
+func (f *Fake) StatsWithQuery(siteID int, query vrm.StatsQuery) (*vrm.StatsResponse, error) {
+	return f.StatsWithQueryContext(context.Background(), siteID, query)
+}
+
+// StatsWithQueryContext returns the site's stats restricted to the query's period and attribute codes.
+// The interval and type are not applied.
+func (f *Fake) StatsWithQueryContext(ctx context.Context, siteID int, query vrm.StatsQuery) (*vrm.StatsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "StatsWithQuery", siteID, query); err != nil {
+		return nil, err
+	}
+	return f.stats(siteID, query)
+}
+
+// stats returns the site's stats restricted to the query. The lock must be held.
+func (f *Fake) stats(siteID int, query vrm.StatsQuery) (*vrm.StatsResponse, error) {
+	if query.Type == vrm.StatsTypeCustom && len(query.AttributeCodes) == 0 {
+		return nil, errors.New("custom stats require at least one attribute code")
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.Stats == nil {
+		return nil, notFound("no stats for installation %d", siteID)
+	}
+
+	data := *site.Stats
+	data.Records = vrm.StatsRecords{}
+	for code, series := range site.Stats.Records {
+		if len(query.AttributeCodes) > 0 && !contains(query.AttributeCodes, code) {
+			continue
+		}
+		data.Records[code] = between(series, query.Start, query.End)
+	}
+	data.Totals.Codes = make(map[string]float64, len(site.Stats.Totals.Codes))
+	for code, total := range site.Stats.Totals.Codes {
+		data.Totals.Codes[code] = total
+	}
+	return &data, nil
+}
+
+// between returns the points of the series within start and end, which are open if zero.
+func between(series vrm.Series, start, end time.Time) vrm.Series {
+	var points vrm.Series
+	for _, p := range series {
+		if (start.IsZero() || !p.Time.Before(start)) && (end.IsZero() || !p.Time.After(end)) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fake) DownloadData(siteID int) ([]byte, error) {
+	return f.DownloadDataContext(context.Background(), siteID)
+}
+
+func (f *Fake) DownloadDataContext(ctx context.Context, siteID int) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DownloadData", siteID); err != nil {
+		return nil, err
+	}
+
+	var data bytes.Buffer
+	if _, err := f.download(&data, siteID); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+func (f *Fake) DownloadDataTo(w io.Writer, siteID int, query vrm.DownloadQuery) (int64, error) {
+	return f.DownloadDataToContext(context.Background(), w, siteID, query)
+}
+
+// DownloadDataToContext writes the site's download as is, regardless of the query.
+func (f *Fake) DownloadDataToContext(ctx context.Context, w io.Writer, siteID int, query vrm.DownloadQuery) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DownloadDataTo", siteID, query); err != nil {
+		return 0, err
+	}
+	return f.download(w, siteID)
+}
+
+// download writes the site's download. The lock must be held.
+func (f *Fake) download(w io.Writer, siteID int) (int64, error) {
+	site, err := f.site(siteID)
+	if err != nil {
+		return 0, err
+	}
+	if site.Download == nil {
+		return 0, notFound("no download for installation %d", siteID)
+	}
+	n, err := w.Write(site.Download)
+	return int64(n), err
+}
+
+func (f *Fake) GPSDownload(siteID int, start, end time.Time) (vrm.GPSTrack, error) {
+	return f.GPSDownloadContext(context.Background(), siteID, start, end)
+}
+
+// GPSDownloadContext returns the points of the site's track within the period. Points without a time
+// are always included.
+func (f *Fake) GPSDownloadContext(ctx context.Context, siteID int, start, end time.Time) (vrm.GPSTrack, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GPSDownload", siteID, start, end); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.GPSTrack == nil {
+		return nil, notFound("no GPS track for installation %d", siteID)
+	}
+	var track vrm.GPSTrack
+	for _, p := range site.GPSTrack {
+		if p.Time.IsZero() || (!p.Time.Before(start) && !p.Time.After(end)) {
+			track = append(track, p)
+		}
+	}
+	return track, nil
+}
+
+func (f *Fake) Widget(siteID int, name string, query vrm.WidgetQuery) (*vrm.WidgetResponse, error) {
+	return f.WidgetContext(context.Background(), siteID, name, query)
+}
+
+// WidgetContext returns the site's widget response as is, regardless of the query.
+func (f *Fake) WidgetContext(ctx context.Context, siteID int, name string, query vrm.WidgetQuery) (*vrm.WidgetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Widget", siteID, name, query); err != nil {
+		return nil, err
+	}
+	return f.widget(siteID, name)
+}
+
+// widget returns a widget response of a site. The lock must be held.
+func (f *Fake) widget(siteID int, name string) (*vrm.WidgetResponse, error) {
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	widget, ok := site.Widgets[name]
+	if !ok {
+		return nil, notFound("no %s widget for installation %d", name, siteID)
+	}
+	data := *widget
+	data.Records.Attributes = make(map[string]vrm.WidgetAttribute, len(widget.Records.Attributes))
+	for id, attr := range widget.Records.Attributes {
+		data.Records.Attributes[id] = attr
+	}
+	data.Records.Meta = make(map[string]json.RawMessage, len(widget.Records.Meta))
+	for id, meta := range widget.Records.Meta {
+		data.Records.Meta[id] = meta
+	}
+	data.Records.Raw = append(json.RawMessage(nil), widget.Records.Raw...)
+	return &data, nil
+}
+
+// typedWidget records a call of a typed widget method and returns the site's response of the widget.
+func (f *Fake) typedWidget(ctx context.Context, method string, siteID int, name string, args ...interface{}) (*vrm.WidgetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, method, append([]interface{}{siteID}, args...)...); err != nil {
+		return nil, err
+	}
+	return f.widget(siteID, name)
+}
+
+func (f *Fake) BatterySummary(siteID int, instance int) (*vrm.BatterySummary, error) {
+	return f.BatterySummaryContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) BatterySummaryContext(ctx context.Context, siteID int, instance int) (*vrm.BatterySummary, error) {
+	widget, err := f.typedWidget(ctx, "BatterySummary", siteID, vrm.WidgetBatterySummary, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.BatterySummary(), nil
+}
+
+func (f *Fake) MPPTState(siteID int, instance int) (*vrm.DeviceState, error) {
+	return f.MPPTStateContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) MPPTStateContext(ctx context.Context, siteID int, instance int) (*vrm.DeviceState, error) {
+	widget, err := f.typedWidget(ctx, "MPPTState", siteID, vrm.WidgetMPPTState, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.DeviceState(), nil
+}
+
+func (f *Fake) VeBusState(siteID int, instance int) (*vrm.DeviceState, error) {
+	return f.VeBusStateContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) VeBusStateContext(ctx context.Context, siteID int, instance int) (*vrm.DeviceState, error) {
+	widget, err := f.typedWidget(ctx, "VeBusState", siteID, vrm.WidgetVeBusState, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.DeviceState(), nil
+}
+
+func (f *Fake) SolarChargerSummary(siteID int, instance int) (*vrm.SolarChargerSummary, error) {
+	return f.SolarChargerSummaryContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) SolarChargerSummaryContext(ctx context.Context, siteID int, instance int) (*vrm.SolarChargerSummary, error) {
+	widget, err := f.typedWidget(ctx, "SolarChargerSummary", siteID, vrm.WidgetSolarChargerSummary, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.SolarChargerSummary(), nil
+}
+
+func (f *Fake) BMSDiagnostics(siteID int, instance int) (*vrm.BMSSummary, error) {
+	return f.BMSDiagnosticsContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) BMSDiagnosticsContext(ctx context.Context, siteID int, instance int) (*vrm.BMSSummary, error) {
+	widget, err := f.typedWidget(ctx, "BMSDiagnostics", siteID, vrm.WidgetBMSDiagnostics, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.BMSSummary(), nil
+}
+
+func (f *Fake) LithiumBMS(siteID int, instance int) (*vrm.BMSSummary, error) {
+	return f.LithiumBMSContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) LithiumBMSContext(ctx context.Context, siteID int, instance int) (*vrm.BMSSummary, error) {
+	widget, err := f.typedWidget(ctx, "LithiumBMS", siteID, vrm.WidgetLithiumBMS, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.BMSSummary(), nil
+}
+
+func (f *Fake) PVInverterStatus(siteID int, instance int) (*vrm.PVInverterStatus, error) {
+	return f.PVInverterStatusContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) PVInverterStatusContext(ctx context.Context, siteID int, instance int) (*vrm.PVInverterStatus, error) {
+	widget, err := f.typedWidget(ctx, "PVInverterStatus", siteID, vrm.WidgetPVInverterStatus, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.PVInverterStatus(), nil
+}
+
+func (f *Fake) StatusWidget(siteID int) (*vrm.DeviceState, error) {
+	return f.StatusWidgetContext(context.Background(), siteID)
+}
+
+func (f *Fake) StatusWidgetContext(ctx context.Context, siteID int) (*vrm.DeviceState, error) {
+	widget, err := f.typedWidget(ctx, "StatusWidget", siteID, vrm.WidgetStatus)
+	if err != nil {
+		return nil, err
+	}
+	return widget.DeviceState(), nil
+}
+
+func (f *Fake) MotorSummary(siteID int, instance int) (*vrm.MotorSummary, error) {
+	return f.MotorSummaryContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) MotorSummaryContext(ctx context.Context, siteID int, instance int) (*vrm.MotorSummary, error) {
+	widget, err := f.typedWidget(ctx, "MotorSummary", siteID, vrm.WidgetMotorSummary, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.MotorSummary(), nil
+}
+
+func (f *Fake) IOExtenderInOut(siteID int, instance int) (*vrm.IOExtenderInOut, error) {
+	return f.IOExtenderInOutContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) IOExtenderInOutContext(ctx context.Context, siteID int, instance int) (*vrm.IOExtenderInOut, error) {
+	widget, err := f.typedWidget(ctx, "IOExtenderInOut", siteID, vrm.WidgetIOExtenderInOut, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.IOExtenderInOut(), nil
+}
+
+func (f *Fake) HistoricData(siteID int, instance int) (*vrm.HistoricData, error) {
+	return f.HistoricDataContext(context.Background(), siteID, instance)
+}
+
+func (f *Fake) HistoricDataContext(ctx context.Context, siteID int, instance int) (*vrm.HistoricData, error) {
+	widget, err := f.typedWidget(ctx, "HistoricData", siteID, vrm.WidgetHistoricData, instance)
+	if err != nil {
+		return nil, err
+	}
+	return widget.HistoricData(), nil
+}
+
+func (f *Fake) GPS(siteID int) (*vrm.GPSPosition, error) {
+	return f.GPSContext(context.Background(), siteID)
+}
+
+func (f *Fake) GPSContext(ctx context.Context, siteID int) (*vrm.GPSPosition, error) {
+	widget, err := f.typedWidget(ctx, "GPS", siteID, vrm.WidgetGPS)
+	if err != nil {
+		return nil, err
+	}
+	return widget.GPSPosition(), nil
+}
+
+func (f *Fake) Graph(siteID int, query vrm.WidgetQuery) (*vrm.GraphResponse, error) {
+	return f.GraphContext(context.Background(), siteID, query)
+}
+
+// GraphContext returns the site's graph response restricted to the query's period. The attributes are
+// not selected by the query.
+func (f *Fake) GraphContext(ctx context.Context, siteID int, query vrm.WidgetQuery) (*vrm.GraphResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Graph", siteID, query); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.Graph == nil {
+		return nil, notFound("no %s widget for installation %d", vrm.WidgetGraph, siteID)
+	}
+
+	data := *site.Graph
+	data.Records.Data = vrm.StatsRecords{}
+	for id, series := range site.Graph.Records.Data {
+		data.Records.Data[id] = between(series, query.Start, query.End)
+	}
+	data.Records.Meta = make(map[string]vrm.GraphMeta, len(site.Graph.Records.Meta))
+	for id, meta := range site.Graph.Records.Meta {
+		data.Records.Meta[id] = meta
+	}
+	return &data, nil
+}
+
+func (f *Fake) AlarmWidget(siteID int) (*vrm.AlarmWidgetResponse, error) {
+	return f.AlarmWidgetContext(context.Background(), siteID)
+}
+
+func (f *Fake) AlarmWidgetContext(ctx context.Context, siteID int) (*vrm.AlarmWidgetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "AlarmWidget", siteID); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.AlarmWidget == nil {
+		return nil, notFound("no %s widget for installation %d", vrm.WidgetAlarm, siteID)
+	}
+	data := *site.AlarmWidget
+	data.Records.Alarms = append(data.Records.Alarms[:0:0], data.Records.Alarms...)
+	data.Records.Devices = append(data.Records.Devices[:0:0], data.Records.Devices...)
+	return &data, nil
+}
+
+func (f *Fake) HoursOfAC(siteID int, start, end time.Time) (*vrm.HoursOfACResponse, error) {
+	return f.HoursOfACContext(context.Background(), siteID, start, end)
+}
+
+func (f *Fake) HoursOfACContext(ctx context.Context, siteID int, start, end time.Time) (*vrm.HoursOfACResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "HoursOfAC", siteID, start, end); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.HoursOfAC == nil {
+		return nil, notFound("no %s widget for installation %d", vrm.WidgetHoursOfAC, siteID)
+	}
+	data := *site.HoursOfAC
+	data.Records.Data = append(vrm.Series(nil), data.Records.Data...)
+	return &data, nil
+}
+
+func (f *Fake) AlarmRules(siteID int) (*vrm.AlarmRulesResponse, error) {
+	return f.AlarmRulesContext(context.Background(), siteID)
+}
+
+func (f *Fake) AlarmRulesContext(ctx context.Context, siteID int) (*vrm.AlarmRulesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "AlarmRules", siteID); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	return &vrm.AlarmRulesResponse{Success: true, Rules: append([]vrm.AlarmRule(nil), site.AlarmRules...)}, nil
+}
+
+// alarmRule returns the index of a site's rule for the attribute and instance or -1.
+func (s *Site) alarmRule(dataAttributeID int, instance int) int {
+	for i, rule := range s.AlarmRules {
+		if rule.DataAttributeID == dataAttributeID && rule.Instance == instance {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *Fake) CreateAlarmRule(siteID int, rule vrm.AlarmRule) (*vrm.AlarmRuleResponse, error) {
+	return f.CreateAlarmRuleContext(context.Background(), siteID, rule)
+}
+
+func (f *Fake) CreateAlarmRuleContext(ctx context.Context, siteID int, rule vrm.AlarmRule) (*vrm.AlarmRuleResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "CreateAlarmRule", siteID, rule); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	if site.alarmRule(rule.DataAttributeID, rule.Instance) >= 0 {
+		return nil, &vrm.APIError{
+			StatusCode: http.StatusConflict,
+			ErrorCode:  "alarm_exists",
+			Message:    fmt.Sprintf("alarm rule for attribute %d instance %d exists", rule.DataAttributeID, rule.Instance),
+		}
+	}
+	site.AlarmRules = append(site.AlarmRules, rule)
+	return &vrm.AlarmRuleResponse{Success: true}, nil
+}
+
+func (f *Fake) UpdateAlarmRule(siteID int, rule vrm.AlarmRule) (*vrm.AlarmRuleResponse, error) {
+	return f.UpdateAlarmRuleContext(context.Background(), siteID, rule)
+}
+
+func (f *Fake) UpdateAlarmRuleContext(ctx context.Context, siteID int, rule vrm.AlarmRule) (*vrm.AlarmRuleResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UpdateAlarmRule", siteID, rule); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	i := site.alarmRule(rule.DataAttributeID, rule.Instance)
+	if i < 0 {
+		return nil, notFound("no alarm rule for attribute %d instance %d", rule.DataAttributeID, rule.Instance)
+	}
+	site.AlarmRules[i] = rule
+	return &vrm.AlarmRuleResponse{Success: true}, nil
+}
+
+func (f *Fake) DeleteAlarmRule(siteID int, dataAttributeID int, instance int) (*vrm.AlarmRuleResponse, error) {
+	return f.DeleteAlarmRuleContext(context.Background(), siteID, dataAttributeID, instance)
+}
+
+func (f *Fake) DeleteAlarmRuleContext(ctx context.Context, siteID int, dataAttributeID int, instance int) (*vrm.AlarmRuleResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "DeleteAlarmRule", siteID, dataAttributeID, instance); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	i := site.alarmRule(dataAttributeID, instance)
+	if i < 0 {
+		return nil, notFound("no alarm rule for attribute %d instance %d", dataAttributeID, instance)
+	}
+	site.AlarmRules = append(site.AlarmRules[:i], site.AlarmRules[i+1:]...)
+	return &vrm.AlarmRuleResponse{Success: true}, nil
+}
+
+func (f *Fake) Alarms(siteID int, query vrm.AlarmQuery) (*vrm.AlarmsResponse, error) {
+	return f.AlarmsContext(context.Background(), siteID, query)
+}
+
+// AlarmsContext returns the site's alarms started within the query's period.
+func (f *Fake) AlarmsContext(ctx context.Context, siteID int, query vrm.AlarmQuery) (*vrm.AlarmsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "Alarms", siteID, query); err != nil {
+		return nil, err
+	}
+
+	site, err := f.site(siteID)
+	if err != nil {
+		return nil, err
+	}
+	data := vrm.AlarmsResponse{Success: true}
+	for _, alarm := range site.Alarms {
+		if query.ActiveOnly && !alarm.Active() {
+			continue
+		}
+		if (!query.Start.IsZero() && alarm.Started < query.Start.Unix()) || (!query.End.IsZero() && alarm.Started > query.End.Unix()) {
+			continue
+		}
+		data.Alarms = append(data.Alarms, alarm)
+	}
+	return &data, nil
+}
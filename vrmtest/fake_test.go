@@ -0,0 +1,253 @@
+package vrmtest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+	"github.com/christianschmizz/go-victron/vrmtest"
+)
+
+// countDevices stands for code under test which depends on a session.
+func countDevices(session vrm.Session) (int, error) {
+	devices := 0
+	it := session.AllInstallations(vrm.InstallationsQuery{})
+	for it.Next() {
+		overview, err := session.SystemOverview(it.Installation().SiteID)
+		if err != nil {
+			return 0, err
+		}
+		devices += len(overview.Records.Devices)
+	}
+	return devices, it.Err()
+}
+
+func TestFakeFixtures(t *testing.T) {
+	fake := vrmtest.NewFake(vrm.DemoUserID)
+	if !assert.NoError(t, fake.LoadFixtures("testdata")) {
+		return
+	}
+	assert.Equal(t, vrm.DemoUserID, fake.UserID())
+
+	devices, err := countDevices(fake)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, devices)
+	}
+
+	tagged, err := fake.InstallationsByTag("customer-x")
+	if assert.NoError(t, err) && assert.Len(t, tagged, 1) {
+		assert.Equal(t, "Demo Boat", tagged[0].Name)
+	}
+
+	diag, err := fake.Diagnostics(1234, 2)
+	if assert.NoError(t, err) {
+		assert.Len(t, diag.Records, 2)
+		assert.Equal(t, uint(2), diag.NumRecords)
+	}
+
+	battery, err := fake.BatterySummary(1234, 288)
+	if assert.NoError(t, err) && assert.NotNil(t, battery.StateOfCharge) {
+		assert.Equal(t, 95.5, *battery.StateOfCharge)
+	}
+
+	state, err := fake.MPPTState(1234, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Bulk", state.Name)
+	}
+
+	stats, err := fake.StatsWithQuery(1234, vrm.StatsQuery{AttributeCodes: []string{"Pc"}})
+	if assert.NoError(t, err) {
+		assert.Len(t, stats.Records, 1)
+		assert.InDelta(t, 1.31, stats.Records["Pc"].Sum(), 1e-9)
+	}
+
+	csv, err := fake.DownloadData(1234)
+	if assert.NoError(t, err) {
+		_, rows, err := vrm.ParseCSVExport(bytes.NewReader(csv), nil)
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+	}
+
+	_, err = fake.Diagnostics(5678, 0)
+	assert.True(t, vrm.IsNotFound(err))
+	_, err = fake.SystemOverview(42)
+	assert.True(t, vrm.IsNotFound(err))
+}
+
+func TestFakeChanges(t *testing.T) {
+	fake := vrmtest.NewFake(1)
+	site := fake.AddSite(vrm.Installation{SiteID: 1234, Name: "Cabin"})
+	site.AlarmRules = []vrm.AlarmRule{{DataAttributeID: 51, Instance: 288, Enabled: true}}
+
+	_, err := fake.AddTag(1234, "customer-x")
+	assert.NoError(t, err)
+	install, err := fake.Installation(1234)
+	if assert.NoError(t, err) {
+		assert.True(t, install.HasTag("customer-x"))
+	}
+
+	_, err = fake.CreateAlarmRule(1234, vrm.AlarmRule{DataAttributeID: 51, Instance: 288})
+	assert.Error(t, err)
+	_, err = fake.UpdateAlarmRule(1234, vrm.AlarmRule{DataAttributeID: 51, Instance: 288, Enabled: false})
+	assert.NoError(t, err)
+	_, err = fake.CreateAlarmRule(1234, vrm.AlarmRule{DataAttributeID: 47, Instance: 288})
+	assert.NoError(t, err)
+	_, err = fake.DeleteAlarmRule(1234, 51, 288)
+	assert.NoError(t, err)
+
+	rules, err := fake.AlarmRules(1234)
+	if assert.NoError(t, err) && assert.Len(t, rules.Rules, 1) {
+		assert.Equal(t, 47, rules.Rules[0].DataAttributeID)
+	}
+
+	created, err := fake.CreateAccessToken("collector", time.Time{})
+	if assert.NoError(t, err) {
+		_, err = fake.RevokeAccessToken(created.AccessTokenID)
+		assert.NoError(t, err)
+	}
+	tokens, err := fake.ListAccessTokens()
+	if assert.NoError(t, err) {
+		assert.Empty(t, tokens.Tokens)
+	}
+
+	assert.Len(t, fake.CallsTo("CreateAlarmRule"), 2)
+}
+
+func TestFakeErrors(t *testing.T) {
+	fake := vrmtest.NewFake(1)
+	fake.AddSite(vrm.Installation{SiteID: 1234})
+
+	fake.Errors["SystemOverview"] = &vrm.APIError{StatusCode: 429}
+	_, err := countDevices(fake)
+	assert.True(t, vrm.IsRateLimited(err))
+
+	fake.Errors["AllInstallations"] = errors.New("boom")
+	_, err = countDevices(fake)
+	assert.EqualError(t, err, "boom")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = fake.InstallationContext(ctx, 1234)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestFakeErrorsByMethod(t *testing.T) {
+	fake := vrmtest.NewFake(1)
+	site := fake.AddSite(vrm.Installation{SiteID: 1234})
+	site.Stats = &vrm.StatsResponse{Success: true}
+	site.Download = []byte("timestamp\n")
+
+	boom := errors.New("boom")
+	fake.Errors["Stats"] = boom
+	fake.Errors["Users"] = boom
+	fake.Errors["DownloadData"] = boom
+	fake.Errors["AllUsers"] = boom
+
+	_, err := fake.Stats(1234)
+	assert.Equal(t, boom, err)
+	_, err = fake.Users()
+	assert.Equal(t, boom, err)
+	_, err = fake.DownloadData(1234)
+	assert.Equal(t, boom, err)
+	it := fake.AllUsers(vrm.UsersQuery{})
+	assert.False(t, it.Next())
+	assert.Equal(t, boom, it.Err())
+
+	// The variants taking a query are separate methods
+	_, err = fake.StatsWithQuery(1234, vrm.StatsQuery{})
+	assert.NoError(t, err)
+	_, err = fake.UsersWithQuery(vrm.UsersQuery{})
+	assert.NoError(t, err)
+	_, err = fake.DownloadDataTo(ioutil.Discard, 1234, vrm.DownloadQuery{})
+	assert.NoError(t, err)
+
+	for _, method := range []string{"Stats", "StatsWithQuery", "Users", "UsersWithQuery", "DownloadData", "DownloadDataTo", "AllUsers"} {
+		assert.Len(t, fake.CallsTo(method), 1, method)
+	}
+
+	fake.AddSite(vrm.Installation{SiteID: 5678})
+	sites := 0
+	installs := fake.AllInstallations(vrm.InstallationsQuery{Count: 1})
+	for installs.Next() {
+		sites++
+	}
+	assert.NoError(t, installs.Err())
+	assert.Equal(t, 2, sites)
+	assert.Len(t, fake.CallsTo("AllInstallations"), 1, "pages are not recorded")
+}
+
+func TestFakeIDsAreNotReused(t *testing.T) {
+	fake := vrmtest.NewFake(1)
+	fake.AddSite(vrm.Installation{SiteID: 1234})
+
+	first, err := fake.CreateAccessToken("first", time.Time{})
+	assert.NoError(t, err)
+	second, err := fake.CreateAccessToken("second", time.Time{})
+	assert.NoError(t, err)
+	_, err = fake.RevokeAccessToken(first.AccessTokenID)
+	assert.NoError(t, err)
+	third, err := fake.CreateAccessToken("third", time.Time{})
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, second.AccessTokenID, third.AccessTokenID)
+	}
+
+	for _, tag := range []string{"a", "b"} {
+		_, err = fake.AddTag(1234, tag)
+		assert.NoError(t, err)
+	}
+	_, err = fake.RemoveTag(1234, "a")
+	assert.NoError(t, err)
+	_, err = fake.AddTag(1234, "c")
+	assert.NoError(t, err)
+	install, err := fake.Installation(1234)
+	if assert.NoError(t, err) && assert.Len(t, install.Tags, 2) {
+		assert.NotEqual(t, install.Tags[0].TagID, install.Tags[1].TagID)
+	}
+}
+
+func TestFakeReturnsCopies(t *testing.T) {
+	fake := vrmtest.NewFake(vrm.DemoUserID)
+	if !assert.NoError(t, fake.LoadFixtures("testdata")) {
+		return
+	}
+
+	overview, err := fake.SystemOverview(1234)
+	if assert.NoError(t, err) && assert.NotEmpty(t, overview.Records.Devices) {
+		name := overview.Records.Devices[0].Name
+		overview.Records.Devices[0].Name = "changed"
+		overview, err = fake.SystemOverview(1234)
+		if assert.NoError(t, err) {
+			assert.Equal(t, name, overview.Records.Devices[0].Name)
+		}
+	}
+
+	widget, err := fake.Widget(1234, vrm.WidgetBatterySummary, vrm.WidgetQuery{})
+	if assert.NoError(t, err) {
+		attributes := len(widget.Records.Attributes)
+		for id := range widget.Records.Attributes {
+			delete(widget.Records.Attributes, id)
+		}
+		widget, err = fake.Widget(1234, vrm.WidgetBatterySummary, vrm.WidgetQuery{})
+		if assert.NoError(t, err) {
+			assert.Len(t, widget.Records.Attributes, attributes)
+		}
+	}
+
+	stats, err := fake.Stats(1234)
+	if assert.NoError(t, err) {
+		stats.Totals.Codes["Pc"] = 100
+		stats, err = fake.Stats(1234)
+		if assert.NoError(t, err) {
+			assert.NotEqual(t, 100.0, stats.Totals.Codes["Pc"])
+		}
+	}
+
+	_, err = fake.StatsWithQuery(1234, vrm.StatsQuery{Type: vrm.StatsTypeCustom})
+	assert.Error(t, err, "custom stats require attribute codes")
+}
@@ -0,0 +1,203 @@
+package vrmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+// LoadFixture decodes the JSON file at path into v.
+func LoadFixture(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadFixtures fills the fake with the responses found in dir. The files are laid out like the API's
+// paths, holding the response bodies VRM sends:
+//
+//	admin/users.json
+//	users/<user ID>/installations.json
+//	users/<user ID>/accesstokens/list.json
+//	installations/<site ID>/system-overview.json
+//	installations/<site ID>/diagnostics.json
+//	installations/<site ID>/stats.json
+//	installations/<site ID>/settings.json
+//	installations/<site ID>/users.json
+//	installations/<site ID>/tags.json
+//	installations/<site ID>/alarms.json
+//	installations/<site ID>/alarm-log.json
+//	installations/<site ID>/data-download.<format>
+//	installations/<site ID>/gps-download.kml
+//	installations/<site ID>/widgets/<widget name>.json
+//
+// Sites are created from the installations listed for a user. Fixtures of an unlisted site create a
+// site of the fake's user. Unknown files are ignored.
+func (f *Fake) LoadFixtures(dir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Installations first as they carry the sites' users
+	listings, err := filepath.Glob(filepath.Join(dir, "users", "*", "installations.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range listings {
+		userID, err := strconv.Atoi(filepath.Base(filepath.Dir(path)))
+		if err != nil {
+			continue
+		}
+		installs := vrm.InstallationsResponse{}
+		if err := LoadFixture(path, &installs); err != nil {
+			return err
+		}
+		for _, install := range installs.Records {
+			if install.UserID == 0 {
+				install.UserID = userID
+			}
+			f.fixtureSite(install.SiteID).Installation = install
+		}
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return f.loadFixture(strings.Split(filepath.ToSlash(rel), "/"), path)
+	})
+}
+
+// fixtureSite returns the site with the given ID, creating it if necessary. The lock must be held.
+func (f *Fake) fixtureSite(siteID int) *Site {
+	site, ok := f.Sites[siteID]
+	if !ok {
+		site = &Site{
+			Installation: vrm.Installation{SiteID: siteID, UserID: f.userID},
+			Widgets:      map[string]*vrm.WidgetResponse{},
+		}
+		f.Sites[siteID] = site
+	}
+	return site
+}
+
+// loadFixture loads a single fixture file given by the elements of its path relative to the fixture directory.
+func (f *Fake) loadFixture(elems []string, path string) error {
+	switch {
+	case len(elems) == 2 && elems[0] == "admin" && elems[1] == "users.json":
+		users := vrm.UsersResponse{}
+		if err := LoadFixture(path, &users); err != nil {
+			return err
+		}
+		f.AdminUsers = users.Users
+		return nil
+
+	case len(elems) == 4 && elems[0] == "users" && elems[2] == "accesstokens" && elems[3] == "list.json":
+		tokens := vrm.AccessTokensResponse{}
+		if err := LoadFixture(path, &tokens); err != nil {
+			return err
+		}
+		f.AccessTokens = tokens.Tokens
+		return nil
+
+	case len(elems) < 3 || elems[0] != "installations":
+		return nil
+	}
+
+	siteID, err := strconv.Atoi(elems[1])
+	if err != nil {
+		return nil
+	}
+	site := f.fixtureSite(siteID)
+
+	if len(elems) == 4 && elems[2] == "widgets" && filepath.Ext(elems[3]) == ".json" {
+		name := strings.TrimSuffix(elems[3], ".json")
+		switch name {
+		case vrm.WidgetAlarm:
+			site.AlarmWidget = &vrm.AlarmWidgetResponse{}
+			return LoadFixture(path, site.AlarmWidget)
+		case vrm.WidgetHoursOfAC:
+			site.HoursOfAC = &vrm.HoursOfACResponse{}
+			return LoadFixture(path, site.HoursOfAC)
+		case vrm.WidgetGraph:
+			site.Graph = &vrm.GraphResponse{}
+			return LoadFixture(path, site.Graph)
+		}
+		widget := &vrm.WidgetResponse{}
+		if err := LoadFixture(path, widget); err != nil {
+			return err
+		}
+		site.Widgets[name] = widget
+		return nil
+	}
+	if len(elems) != 3 {
+		return nil
+	}
+
+	switch name := elems[2]; {
+	case name == "system-overview.json":
+		site.SystemOverview = &vrm.SystemOverviewResponse{}
+		return LoadFixture(path, site.SystemOverview)
+	case name == "diagnostics.json":
+		site.Diagnostics = &vrm.DiagnosticsResponse{}
+		return LoadFixture(path, site.Diagnostics)
+	case name == "stats.json":
+		site.Stats = &vrm.StatsResponse{}
+		return LoadFixture(path, site.Stats)
+	case name == "settings.json":
+		settings := vrm.InstallationSettingsResponse{}
+		if err := LoadFixture(path, &settings); err != nil {
+			return err
+		}
+		site.Settings = &settings.Data
+	case name == "users.json":
+		site.Users = &vrm.SiteUsersResponse{}
+		return LoadFixture(path, site.Users)
+	case name == "tags.json":
+		tags := vrm.TagsResponse{}
+		if err := LoadFixture(path, &tags); err != nil {
+			return err
+		}
+		site.Installation.Tags = tags.Tags
+	case name == "alarms.json":
+		rules := vrm.AlarmRulesResponse{}
+		if err := LoadFixture(path, &rules); err != nil {
+			return err
+		}
+		site.AlarmRules = rules.Rules
+	case name == "alarm-log.json":
+		alarms := vrm.AlarmsResponse{}
+		if err := LoadFixture(path, &alarms); err != nil {
+			return err
+		}
+		site.Alarms = alarms.Alarms
+	case strings.HasPrefix(name, "data-download."):
+		if site.Download, err = ioutil.ReadFile(path); err != nil {
+			return err
+		}
+	case name == "gps-download.kml":
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if site.GPSTrack, err = vrm.ParseKML(file); err != nil {
+			return fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package vrmtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+// Redacted replaces secrets in recorded interactions.
+const Redacted = "REDACTED"
+
+// DefaultRedactedFields are the JSON fields and query parameters scrubbed from recordings.
+var DefaultRedactedFields = []string{"password", "sms_token", "token"}
+
+// Headers scrubbed from recordings; the authorization scheme is kept
+var redactedHeaders = []string{"X-Authorization", "Authorization", "Cookie", "Set-Cookie"}
+
+// Message is the recorded request or response part of an interaction. The body is kept as JSON if
+// possible, as text if it is valid UTF-8 and as raw bytes otherwise.
+type Message struct {
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Text   string          `json:"text,omitempty"`
+	Raw    []byte          `json:"raw,omitempty"`
+}
+
+func (m *Message) body() []byte {
+	switch {
+	case len(m.JSON) > 0:
+		return m.JSON
+	case len(m.Text) > 0:
+		return []byte(m.Text)
+	}
+	return m.Raw
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Method string `json:"method"`
+	// Path and query of the request URL
+	URL      string  `json:"url"`
+	Request  Message `json:"request"`
+	Status   int     `json:"status"`
+	Response Message `json:"response"`
+}
+
+// Recorder is a vrm.HTTPClient passing requests on to another client and recording the interactions
+// with secrets scrubbed, to be saved as golden file and served by a Replayer later:
+//
+//	recorder := vrmtest.NewRecorder(http.DefaultClient)
+//	session, err := vrm.Login(username, password, vrm.WithHTTPClient(recorder))
+//	...
+//	err = recorder.Save("testdata/site.json")
+type Recorder struct {
+	client vrm.HTTPClient
+	// JSON fields and query parameters to scrub, defaults to DefaultRedactedFields
+	RedactedFields []string
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+var _ vrm.HTTPClient = (*Recorder)(nil)
+
+// NewRecorder creates a recorder passing requests on to client.
+func NewRecorder(client vrm.HTTPClient) *Recorder {
+	return &Recorder{
+		client:         client,
+		RedactedFields: DefaultRedactedFields,
+	}
+}
+
+// Do sends the request by the recorder's client and records the interaction unless sending failed.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Method:   req.Method,
+		URL:      redactURL(req.URL, r.RedactedFields),
+		Request:  r.message(req.Header, reqBody),
+		Status:   res.StatusCode,
+		Response: r.message(res.Header, resBody),
+	})
+	return res, nil
+}
+
+// Interactions returns the interactions recorded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save writes the recorded interactions to the golden file at path.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func redacted(fields []string, name string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL returns path and query of the URL with the values of the given query parameters scrubbed.
+func redactURL(u *url.URL, fields []string) string {
+	query := u.Query()
+	for key, values := range query {
+		if redacted(fields, key) {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+	clean := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return clean.String()
+}
+
+func (r *Recorder) message(header http.Header, body []byte) Message {
+	msg := Message{Header: header.Clone()}
+	for _, name := range redactedHeaders {
+		values := msg.Header[http.CanonicalHeaderKey(name)]
+		for i, value := range values {
+			// Keep the scheme, e.g. "Bearer" or "Token"
+			if fields := strings.Fields(value); len(fields) == 2 {
+				values[i] = fields[0] + " " + Redacted
+			} else {
+				values[i] = Redacted
+			}
+		}
+	}
+
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			msg.Raw = body
+			break
+		}
+		data, err := json.Marshal(redactJSON(v, r.RedactedFields))
+		if err != nil {
+			msg.Raw = body
+			break
+		}
+		msg.JSON = data
+	case utf8.Valid(body):
+		msg.Text = string(body)
+	default:
+		msg.Raw = body
+	}
+	return msg
+}
+
+// redactJSON scrubs the values of the given fields in a decoded JSON document.
+func redactJSON(v interface{}, fields []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if redacted(fields, key) && value != nil {
+				v[key] = Redacted
+			} else {
+				v[key] = redactJSON(value, fields)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i], fields)
+		}
+	}
+	return v
+}
+
+// Replayer is a vrm.HTTPClient serving the interactions of a golden file written by a Recorder.
+// A request is answered by the first unused interaction with the same method, path and query. If there
+// is none, e.g. as the query carries a period relative to now, the path alone has to match.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	// Query parameters scrubbed by the recorder, defaults to DefaultRedactedFields
+	RedactedFields []string
+}
+
+var _ vrm.HTTPClient = (*Replayer)(nil)
+
+// NewReplayer loads the golden file at path.
+func NewReplayer(path string) (*Replayer, error) {
+	var interactions []Interaction
+	if err := LoadFixture(path, &interactions); err != nil {
+		return nil, err
+	}
+	return &Replayer{
+		interactions:   interactions,
+		used:           make([]bool, len(interactions)),
+		RedactedFields: DefaultRedactedFields,
+	}, nil
+}
+
+// Do answers the request with the matching recorded response or fails if there is none left.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	u := redactURL(req.URL, r.RedactedFields)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.match(req.Method, u)
+	if i < 0 {
+		i = r.match(req.Method, strings.SplitN(u, "?", 2)[0])
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("no recorded interaction left for %s %s", req.Method, u)
+	}
+	r.used[i] = true
+
+	interaction := r.interactions[i]
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	body := interaction.Response.body()
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// match returns the index of the first unused interaction with the given method and URL or -1. A URL
+// without query matches the path of recorded URLs. The lock must be held.
+func (r *Replayer) match(method, u string) int {
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Method != method {
+			continue
+		}
+		recorded := interaction.URL
+		if !strings.Contains(u, "?") {
+			recorded = strings.SplitN(recorded, "?", 2)[0]
+		}
+		if recorded == u {
+			return i
+		}
+	}
+	return -1
+}
+
+// Unused returns the interactions not replayed so far.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Cassette returns a client for a test using the golden file at path. With record set, requests are
+// passed on to client and recorded to path once the test finished; otherwise they are replayed from path.
+// Tests usually pass a flag like -record to refresh their golden files from the live API.
+func Cassette(t testing.TB, path string, record bool, client vrm.HTTPClient) vrm.HTTPClient {
+	t.Helper()
+	if record {
+		recorder := NewRecorder(client)
+		t.Cleanup(func() {
+			if err := recorder.Save(path); err != nil {
+				t.Errorf("failed to save recording: %v", err)
+			}
+		})
+		return recorder
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("failed to load recording: %v", err)
+	}
+	return replayer
+}
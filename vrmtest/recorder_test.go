@@ -0,0 +1,102 @@
+package vrmtest_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+	"github.com/christianschmizz/go-victron/vrmtest"
+)
+
+// useSession makes the calls recorded and replayed by TestRecordReplay.
+func useSession(t *testing.T, client vrm.HTTPClient, baseURL string) (*vrm.StatsResponse, []byte) {
+	session, err := vrm.Login("user@example.com", "secret", vrm.WithBaseURL(baseURL), vrm.WithHTTPClient(client))
+	if !assert.NoError(t, err) {
+		return nil, nil
+	}
+
+	_, err = session.Installations(vrm.DemoUserID)
+	assert.NoError(t, err)
+
+	_, err = session.SystemOverview(42)
+	assert.True(t, vrm.IsNotFound(err))
+
+	stats, err := session.Stats(1234)
+	assert.NoError(t, err)
+
+	csv, err := session.DownloadData(1234)
+	assert.NoError(t, err)
+
+	return stats, csv
+}
+
+func TestRecordReplay(t *testing.T) {
+	server := vrmtest.NewServer("testdata")
+	server.SetCredentials("user@example.com", "secret", vrm.DemoUserID)
+	golden := filepath.Join(t.TempDir(), "recording.json")
+
+	recorder := vrmtest.NewRecorder(http.DefaultClient)
+	recordedStats, recordedCSV := useSession(t, recorder, server.URL)
+	assert.Len(t, recorder.Interactions(), 5)
+	if !assert.NoError(t, recorder.Save(golden)) {
+		return
+	}
+	server.Close()
+
+	data, err := ioutil.ReadFile(golden)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, string(data), "secret")
+		assert.NotContains(t, string(data), server.Token())
+		assert.Contains(t, string(data), "Bearer "+vrmtest.Redacted)
+	}
+
+	replayer, err := vrmtest.NewReplayer(golden)
+	if !assert.NoError(t, err) {
+		return
+	}
+	replayedStats, replayedCSV := useSession(t, replayer, "https://vrmapi.example.com/")
+	assert.Equal(t, recordedStats, replayedStats)
+	assert.Equal(t, recordedCSV, replayedCSV)
+	assert.Empty(t, replayer.Unused())
+
+	_, err = replayer.Do(newRequest(t, "https://vrmapi.example.com/installations/1234/stats"))
+	assert.Error(t, err)
+}
+
+func newRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestCassette(t *testing.T) {
+	server := vrmtest.NewServer("testdata")
+	defer server.Close()
+	golden := filepath.Join(t.TempDir(), "cassette.json")
+
+	t.Run("record", func(t *testing.T) {
+		client := vrmtest.Cassette(t, golden, true, http.DefaultClient)
+		session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL), vrm.WithHTTPClient(client))
+		if assert.NoError(t, err) {
+			_, err = session.Diagnostics(1234, 10)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		client := vrmtest.Cassette(t, golden, false, nil)
+		session, err := vrm.LoginAsDemo(vrm.WithBaseURL("https://vrmapi.example.com/"), vrm.WithHTTPClient(client))
+		if assert.NoError(t, err) {
+			diag, err := session.Diagnostics(1234, 10)
+			if assert.NoError(t, err) {
+				assert.Len(t, diag.Records, 3)
+			}
+		}
+	})
+}
@@ -0,0 +1,347 @@
+package vrmtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+// Fault is injected into the responses of a Server for requests matching its method and path.
+type Fault struct {
+	// Method to match; empty matches any
+	Method string
+	// Path pattern as understood by path.Match, e.g. "/installations/*/diagnostics"; empty matches any
+	Path string
+	// Status of the response; 0 only delays the request by Latency and serves it as usual
+	Status int
+	// Body of the response; defaults to a VRM error body
+	Body string
+	// Value of the Retry-After header, if any
+	RetryAfter string
+	// Delay before responding
+	Latency time.Duration
+	// Number of requests the fault applies to; 0 applies it to all
+	Times int
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	if len(f.Method) > 0 && f.Method != r.Method {
+		return false
+	}
+	if len(f.Path) == 0 {
+		return true
+	}
+	ok, _ := path.Match(f.Path, r.URL.Path)
+	return ok
+}
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Server is a local stand-in of the VRM API serving fixture files laid out as described for
+// Fake.LoadFixtures. Requests other than logging in must carry the server's token. Write requests
+// are answered with success without changing the fixtures; inspect them by Requests.
+//
+//	server := vrmtest.NewServer("testdata")
+//	defer server.Close()
+//	session, err := vrm.LoginAsDemo(server.Options()...)
+type Server struct {
+	*httptest.Server
+
+	mu  sync.Mutex
+	dir string
+	// Credentials accepted by auth/login; if empty any are accepted
+	username string
+	password string
+	userID   int
+	token    string
+	latency  time.Duration
+	faults   []*Fault
+	requests []Request
+}
+
+// NewServer starts a server serving the fixtures in dir for the demo user.
+func NewServer(dir string) *Server {
+	s := &Server{
+		dir:    dir,
+		userID: vrm.DemoUserID,
+		token:  "vrmtest-token",
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Options returns the options pointing a session at the server.
+func (s *Server) Options() []vrm.Option {
+	return []vrm.Option{vrm.WithBaseURL(s.URL)}
+}
+
+// SetCredentials makes auth/login accept only the given credentials and log in the given user.
+func (s *Server) SetCredentials(username, password string, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username, s.password, s.userID = username, password, userID
+}
+
+// Token returns the token handed out on login and accepted as bearer and access token.
+func (s *Server) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// SetToken replaces the accepted token, e.g. to let the tokens of logged in sessions expire.
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Inject adds a fault. Faults are applied in the order they were added; the first matching one wins.
+func (s *Server) Inject(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes all faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// fault records the request and returns the fault to apply to it, if any.
+func (s *Server) fault(r *http.Request, body []byte) (*Fault, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+
+	for i, fault := range s.faults {
+		if !fault.matches(r) {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return fault, s.latency
+	}
+	return nil, s.latency
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	fault, latency := s.fault(r, body)
+	if fault != nil {
+		latency += fault.Latency
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil && fault.Status != 0 {
+		if len(fault.RetryAfter) > 0 {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		if len(fault.Body) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(fault.Status)
+			fmt.Fprint(w, fault.Body)
+			return
+		}
+		writeError(w, fault.Status, "injected_fault", http.StatusText(fault.Status))
+		return
+	}
+
+	switch r.URL.Path {
+	case "/auth/login":
+		s.login(w, body)
+		return
+	case "/auth/loginAsDemo":
+		s.mu.Lock()
+		token := s.token
+		s.mu.Unlock()
+		writeJSON(w, map[string]interface{}{"token": token, "idUser": strconv.Itoa(vrm.DemoUserID)})
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_token", "Authentication failed")
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/auth/logout":
+		writeJSON(w, map[string]interface{}{"success": true})
+	case r.URL.Path == "/users/me":
+		s.mu.Lock()
+		userID := s.userID
+		s.mu.Unlock()
+		writeJSON(w, map[string]interface{}{"success": true, "user": map[string]interface{}{"id": userID}})
+	case r.Method != http.MethodGet:
+		writeJSON(w, map[string]interface{}{"success": true})
+	default:
+		s.serveFixture(w, r)
+	}
+}
+
+func (s *Server) login(w http.ResponseWriter, body []byte) {
+	req := vrm.LoginRequest{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.username) > 0 && (req.Username != s.username || req.Password != s.password) {
+		writeError(w, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials")
+		return
+	}
+	writeJSON(w, map[string]interface{}{"token": s.token, "idUser": s.userID})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth := r.Header.Get("X-Authorization")
+	return auth == "Bearer "+s.token || auth == "Token "+s.token
+}
+
+// serveFixture serves the fixture file matching the request's path.
+func (s *Server) serveFixture(w http.ResponseWriter, r *http.Request) {
+	name := filepath.Join(s.dir, filepath.FromSlash(strings.TrimPrefix(path.Clean(r.URL.Path), "/")))
+	contentType := "application/json"
+	switch path.Base(r.URL.Path) {
+	case "data-download":
+		format := r.URL.Query().Get("format")
+		if len(format) == 0 {
+			format = string(vrm.DownloadFormatCSV)
+		}
+		name += "." + format
+		contentType = "text/csv"
+		if format != string(vrm.DownloadFormatCSV) {
+			contentType = "application/octet-stream"
+		}
+	case "gps-download":
+		name += ".kml"
+		contentType = "application/vnd.google-earth.kml+xml"
+	default:
+		name += ".json"
+	}
+
+	data, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "not_found", "Resource not found")
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "fixture", err.Error())
+		return
+	}
+
+	if page, count := r.URL.Query().Get("page"), r.URL.Query().Get("count"); len(page) > 0 && len(count) > 0 {
+		data = pageRecords(data, page, count)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
+
+// pageRecords cuts the requested page out of the records of a listing like users/<user ID>/installations.
+// Bodies without records are returned unchanged.
+func pageRecords(data []byte, page, count string) []byte {
+	p, err1 := strconv.Atoi(page)
+	c, err2 := strconv.Atoi(count)
+	if err1 != nil || err2 != nil {
+		return data
+	}
+
+	body := map[string]json.RawMessage{}
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return data
+	}
+	if err := json.Unmarshal(body["records"], &records); err != nil {
+		return data
+	}
+
+	from, to := pageBounds(len(records), p, c)
+	paged, err := json.Marshal(records[from:to])
+	if err != nil {
+		return data
+	}
+	body["records"] = paged
+	repaged, err := json.Marshal(body)
+	if err != nil {
+		return data
+	}
+	return repaged
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		writeError(w, http.StatusInternalServerError, "encoding", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    false,
+		"errors":     message,
+		"error_code": code,
+	})
+}
@@ -0,0 +1,103 @@
+package vrmtest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+	"github.com/christianschmizz/go-victron/vrmtest"
+)
+
+func TestServer(t *testing.T) {
+	server := vrmtest.NewServer("testdata")
+	defer server.Close()
+	server.SetCredentials("user@example.com", "secret", 42)
+
+	_, err := vrm.Login("user@example.com", "wrong", server.Options()...)
+	assert.True(t, vrm.IsUnauthorized(err))
+
+	session, err := vrm.Login("user@example.com", "secret", server.Options()...)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 42, session.UserID())
+
+	installs, err := session.Installations(vrm.DemoUserID)
+	if assert.NoError(t, err) {
+		assert.Len(t, installs.Records, 2)
+	}
+
+	var sites []int
+	it := session.AllInstallations(vrm.InstallationsQuery{UserIDs: []int{vrm.DemoUserID}, Count: 1})
+	for it.Next() {
+		sites = append(sites, it.Installation().SiteID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int{1234, 5678}, sites)
+
+	stats, err := session.StatsWithQuery(1234, vrm.StatsQuery{Type: vrm.StatsTypeKwh, Interval: vrm.IntervalHours})
+	if assert.NoError(t, err) {
+		assert.Len(t, stats.Records["kwh"], 3)
+	}
+
+	battery, err := session.BatterySummary(1234, 288)
+	if assert.NoError(t, err) && assert.NotNil(t, battery.Voltage) {
+		assert.Equal(t, 12.81, *battery.Voltage)
+	}
+
+	var csv bytes.Buffer
+	_, err = session.DownloadDataTo(&csv, 1234, vrm.DownloadQuery{Format: vrm.DownloadFormatCSV})
+	if assert.NoError(t, err) {
+		assert.Contains(t, csv.String(), "Solar yield")
+	}
+
+	_, err = session.Widget(1234, vrm.WidgetLithiumBMS, vrm.WidgetQuery{})
+	assert.True(t, vrm.IsNotFound(err))
+
+	_, err = session.AddTag(1234, "customer-y")
+	assert.NoError(t, err)
+	requests := server.Requests()
+	last := requests[len(requests)-1]
+	assert.Equal(t, http.MethodPut, last.Method)
+	assert.Equal(t, "/installations/1234/tags", last.Path)
+	assert.JSONEq(t, `{"tag": "customer-y"}`, string(last.Body))
+
+	server.SetToken("rotated")
+	_, err = session.SystemOverview(1234)
+	assert.True(t, vrm.IsUnauthorized(err))
+}
+
+func TestServerFaults(t *testing.T) {
+	server := vrmtest.NewServer("testdata")
+	defer server.Close()
+
+	session, err := vrm.LoginAsDemo(append(server.Options(), vrm.WithRetryPolicy(vrm.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}))...)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server.Inject(vrmtest.Fault{Path: "/installations/*/diagnostics", Status: http.StatusServiceUnavailable, Times: 2})
+	_, err = session.Diagnostics(1234, 10)
+	assert.NoError(t, err)
+
+	server.Inject(vrmtest.Fault{Path: "/installations/1234/system-overview", Status: http.StatusTooManyRequests, RetryAfter: "0"})
+	_, err = session.SystemOverview(1234)
+	assert.True(t, vrm.IsRateLimited(err))
+	server.ClearFaults()
+
+	server.Inject(vrmtest.Fault{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = session.SystemOverviewContext(ctx, 1234)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,746 @@
+package vrm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WidgetQuery holds the optional parameters of a widget request.
+type WidgetQuery struct {
+	// Device instance; nil selects VRM's default instance
+	Instance       *int
+	Start          time.Time
+	End            time.Time
+	AttributeCodes []string
+	AttributeIDs   []int
+}
+
+func (q WidgetQuery) values() interface{} {
+	v := struct {
+		Instance       *int     `url:"instance,omitempty"`
+		Start          int64    `url:"start,omitempty"`
+		End            int64    `url:"end,omitempty"`
+		AttributeCodes []string `url:"attributeCodes[],omitempty"`
+		AttributeIDs   []int    `url:"attributeIds[],omitempty"`
+	}{
+		Instance:       q.Instance,
+		AttributeCodes: q.AttributeCodes,
+		AttributeIDs:   q.AttributeIDs,
+	}
+	if !q.Start.IsZero() {
+		v.Start = q.Start.Unix()
+	}
+	if !q.End.IsZero() {
+		v.End = q.End.Unix()
+	}
+	return v
+}
+
+// WidgetAttribute is the current value of a data attribute as reported by a widget.
+type WidgetAttribute struct {
+	DataAttributeID int             `json:"idDataAttribute"`
+	Code            string          `json:"code"`
+	Description     string          `json:"description"`
+	Instance        int             `json:"instance"`
+	Timestamp       int64           `json:"timestamp"`
+	SecondsAgo      int64           `json:"secondsAgo"`
+	RawValue        json.RawMessage `json:"rawValue"`
+	ValueFloat      *float64        `json:"valueFloat"`
+	ValueString     string          `json:"valueString"`
+	ValueEnum       *int            `json:"valueEnum"`
+	NameEnum        string          `json:"nameEnum"`
+	FormattedValue  string          `json:"formattedValue"`
+	FormatWithUnit  string          `json:"formatWithUnit"`
+	IsValid         json.RawMessage `json:"isValid"`
+}
+
+// Float returns the numeric value of the attribute, if any.
+func (a *WidgetAttribute) Float() (float64, bool) {
+	if a.ValueFloat != nil {
+		return *a.ValueFloat, true
+	}
+	var f float64
+	if err := json.Unmarshal(a.RawValue, &f); err == nil {
+		return f, true
+	}
+	var s string
+	if err := json.Unmarshal(a.RawValue, &s); err == nil {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// WidgetRecords holds the attributes of a widget keyed by data attribute ID along with the raw data
+// for widgets reporting more than attributes.
+type WidgetRecords struct {
+	Attributes map[string]WidgetAttribute
+	Meta       map[string]json.RawMessage
+	Raw        json.RawMessage
+}
+
+func (r *WidgetRecords) UnmarshalJSON(data []byte) error {
+	records := WidgetRecords{Raw: append(json.RawMessage(nil), data...)}
+
+	raw := struct {
+		Data map[string]json.RawMessage `json:"data"`
+		Meta map[string]json.RawMessage `json:"meta"`
+	}{}
+	// Some widgets return a list or nothing at all
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		*r = records
+		return nil
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	records.Meta = raw.Meta
+	records.Attributes = map[string]WidgetAttribute{}
+	for key, value := range raw.Data {
+		// Skip flags like hasOldData mixed into the attributes
+		if trimmed := bytes.TrimSpace(value); len(trimmed) == 0 || trimmed[0] != '{' {
+			continue
+		}
+		attr := WidgetAttribute{}
+		if err := json.Unmarshal(value, &attr); err != nil {
+			return fmt.Errorf("could not decode widget attribute %s: %w", key, err)
+		}
+		records.Attributes[key] = attr
+	}
+
+	*r = records
+	return nil
+}
+
+type WidgetResponse struct {
+	Success bool          `json:"success"`
+	Records WidgetRecords `json:"records"`
+}
+
+// attributes returns the attributes ordered by data attribute ID.
+func (w *WidgetResponse) attributes() []WidgetAttribute {
+	attrs := make([]WidgetAttribute, 0, len(w.Records.Attributes))
+	for _, attr := range w.Records.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].DataAttributeID < attrs[j].DataAttributeID })
+	return attrs
+}
+
+// Attribute returns the attribute with the given code and the lowest data attribute ID.
+func (w *WidgetResponse) Attribute(code string) (*WidgetAttribute, bool) {
+	for _, attr := range w.attributes() {
+		if attr.Code == code {
+			return &attr, true
+		}
+	}
+	return nil, false
+}
+
+// float returns the numeric value of the attribute with the given code or nil.
+func (w *WidgetResponse) float(code string) *float64 {
+	attr, ok := w.Attribute(code)
+	if !ok {
+		return nil
+	}
+	f, ok := attr.Float()
+	if !ok {
+		return nil
+	}
+	return &f
+}
+
+// enum returns the first attribute resolving to an enum value.
+func (w *WidgetResponse) enum() (*WidgetAttribute, bool) {
+	for _, attr := range w.attributes() {
+		if attr.ValueEnum != nil || len(attr.NameEnum) > 0 {
+			return &attr, true
+		}
+	}
+	return nil, false
+}
+
+// Retrieve the data of the given widget, e.g. WidgetBatterySummary, for an installation
+func (s *vrmSession) Widget(siteID int, name string, query WidgetQuery) (*WidgetResponse, error) {
+	return s.WidgetContext(context.Background(), siteID, name, query)
+}
+
+// WidgetContext is like Widget but carries a context.
+func (s *vrmSession) WidgetContext(ctx context.Context, siteID int, name string, query WidgetQuery) (*WidgetResponse, error) {
+	data := WidgetResponse{}
+	if err := s.loadWidget(ctx, siteID, name, query, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (s *vrmSession) loadWidget(ctx context.Context, siteID int, name string, query WidgetQuery, resData interface{}) error {
+	url, err := s.formatURL(widgetsURL, URLParams{
+		"siteID":   strconv.Itoa(siteID),
+		"widgetID": name,
+	}, query.values())
+	if err != nil {
+		return err
+	}
+
+	return s.getAndLoad(ctx, url, resData)
+}
+
+// BatterySummary holds the key values of a battery monitor. Values not reported by the device are nil.
+type BatterySummary struct {
+	StateOfCharge    *float64
+	Voltage          *float64
+	Current          *float64
+	Temperature      *float64
+	ConsumedAmphours *float64
+	TimeToGo         *float64
+	Widget           *WidgetResponse
+}
+
+// Retrieve the battery summary of a battery monitor instance
+func (s *vrmSession) BatterySummary(siteID int, instance int) (*BatterySummary, error) {
+	return s.BatterySummaryContext(context.Background(), siteID, instance)
+}
+
+// BatterySummaryContext is like BatterySummary but carries a context.
+func (s *vrmSession) BatterySummaryContext(ctx context.Context, siteID int, instance int) (*BatterySummary, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetBatterySummary, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.BatterySummary(), nil
+}
+
+// BatterySummary extracts the battery summary from the response of a WidgetBatterySummary widget.
+func (w *WidgetResponse) BatterySummary() *BatterySummary {
+	return &BatterySummary{
+		StateOfCharge:    w.float(CodeBatteryMonitorStateOfCharge),
+		Voltage:          w.float(CodeBatteryMonitorVoltage),
+		Current:          w.float(CodeBatteryMonitorCurrent),
+		Temperature:      w.float(CodeBatteryMonitorBatteryTemperature),
+		ConsumedAmphours: w.float(CodeBatteryMonitorConsumedAmphours),
+		TimeToGo:         w.float(CodeBatteryMonitorTimeToGo),
+		Widget:           w,
+	}
+}
+
+// DeviceState is the state of a device as enum value and its name, e.g. "Bulk" or "Inverting".
+type DeviceState struct {
+	Value  int
+	Name   string
+	Widget *WidgetResponse
+}
+
+func (s *vrmSession) deviceState(ctx context.Context, siteID int, name string, instance int) (*DeviceState, error) {
+	w, err := s.WidgetContext(ctx, siteID, name, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.DeviceState(), nil
+}
+
+// DeviceState extracts the device state from the response of a state widget like WidgetMPPTState.
+// Value is -1 if the widget reports no state.
+func (w *WidgetResponse) DeviceState() *DeviceState {
+	attr, _ := w.enum()
+	return w.state(attr)
+}
+
+// state turns an enum attribute into a device state. Value is -1 if attr is nil.
+func (w *WidgetResponse) state(attr *WidgetAttribute) *DeviceState {
+	state := DeviceState{Value: -1, Widget: w}
+	if attr != nil {
+		if attr.ValueEnum != nil {
+			state.Value = *attr.ValueEnum
+		}
+		state.Name = attr.NameEnum
+		if len(state.Name) == 0 {
+			state.Name = attr.FormattedValue
+		}
+	}
+	return &state
+}
+
+// stateOf returns the state reported by the attribute with the given code or nil.
+func (w *WidgetResponse) stateOf(code string) *DeviceState {
+	attr, ok := w.Attribute(code)
+	if !ok {
+		return nil
+	}
+	return w.state(attr)
+}
+
+// flag returns whether the attribute with the given code is set or nil.
+func (w *WidgetResponse) flag(code string) *bool {
+	f := w.float(code)
+	if f == nil {
+		return nil
+	}
+	set := *f != 0
+	return &set
+}
+
+// Retrieve the charge state of a solar charger instance
+func (s *vrmSession) MPPTState(siteID int, instance int) (*DeviceState, error) {
+	return s.MPPTStateContext(context.Background(), siteID, instance)
+}
+
+// MPPTStateContext is like MPPTState but carries a context.
+func (s *vrmSession) MPPTStateContext(ctx context.Context, siteID int, instance int) (*DeviceState, error) {
+	return s.deviceState(ctx, siteID, WidgetMPPTState, instance)
+}
+
+// Retrieve the state of a VE.Bus inverter/charger instance
+func (s *vrmSession) VeBusState(siteID int, instance int) (*DeviceState, error) {
+	return s.VeBusStateContext(context.Background(), siteID, instance)
+}
+
+// VeBusStateContext is like VeBusState but carries a context.
+func (s *vrmSession) VeBusStateContext(ctx context.Context, siteID int, instance int) (*DeviceState, error) {
+	return s.deviceState(ctx, siteID, WidgetVeBusState, instance)
+}
+
+type AlarmWidgetResponse struct {
+	Success bool `json:"success"`
+	Records struct {
+		Alarms []struct {
+			DataAttributeID int    `json:"idDataAttribute"`
+			Instance        int    `json:"instance"`
+			Code            string `json:"code"`
+			Description     string `json:"description"`
+			FormattedValue  string `json:"formattedValue"`
+			NameEnum        string `json:"nameEnum"`
+			Started         int64  `json:"started"`
+			Cleared         int64  `json:"cleared,omitempty"`
+		} `json:"alarms"`
+		Devices []struct {
+			Name     string `json:"name"`
+			Instance int    `json:"instance"`
+		} `json:"devices"`
+	} `json:"records"`
+}
+
+// Retrieve the alarms reported by the alarm widget of an installation
+func (s *vrmSession) AlarmWidget(siteID int) (*AlarmWidgetResponse, error) {
+	return s.AlarmWidgetContext(context.Background(), siteID)
+}
+
+// AlarmWidgetContext is like AlarmWidget but carries a context.
+func (s *vrmSession) AlarmWidgetContext(ctx context.Context, siteID int) (*AlarmWidgetResponse, error) {
+	data := AlarmWidgetResponse{}
+	if err := s.loadWidget(ctx, siteID, WidgetAlarm, WidgetQuery{}, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+type HoursOfACResponse struct {
+	Success bool `json:"success"`
+	Records struct {
+		// Hours per day the installation was connected to AC input, as [timestamp in ms, hours]
+		Data Series `json:"data"`
+	} `json:"records"`
+}
+
+// Retrieve the daily hours an installation was connected to AC input
+func (s *vrmSession) HoursOfAC(siteID int, start, end time.Time) (*HoursOfACResponse, error) {
+	return s.HoursOfACContext(context.Background(), siteID, start, end)
+}
+
+// HoursOfACContext is like HoursOfAC but carries a context.
+func (s *vrmSession) HoursOfACContext(ctx context.Context, siteID int, start, end time.Time) (*HoursOfACResponse, error) {
+	data := HoursOfACResponse{}
+	if err := s.loadWidget(ctx, siteID, WidgetHoursOfAC, WidgetQuery{Start: start, End: end}, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// SolarChargerSummary holds the key values of a solar charger. Values not reported by the device are nil.
+type SolarChargerSummary struct {
+	BatteryVoltage *float64
+	BatteryCurrent *float64
+	PVVoltage      *float64
+	PVPower        *float64
+	// Yields in kWh
+	YieldToday     *float64
+	YieldYesterday *float64
+	State          *DeviceState
+	Error          *DeviceState
+	Widget         *WidgetResponse
+}
+
+// Retrieve the summary of a solar charger instance
+func (s *vrmSession) SolarChargerSummary(siteID int, instance int) (*SolarChargerSummary, error) {
+	return s.SolarChargerSummaryContext(context.Background(), siteID, instance)
+}
+
+// SolarChargerSummaryContext is like SolarChargerSummary but carries a context.
+func (s *vrmSession) SolarChargerSummaryContext(ctx context.Context, siteID int, instance int) (*SolarChargerSummary, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetSolarChargerSummary, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.SolarChargerSummary(), nil
+}
+
+// SolarChargerSummary extracts the solar charger summary from the response of a WidgetSolarChargerSummary widget.
+func (w *WidgetResponse) SolarChargerSummary() *SolarChargerSummary {
+	return &SolarChargerSummary{
+		BatteryVoltage: w.float(CodeSolarChargerBatteryVoltage),
+		BatteryCurrent: w.float(CodeSolarChargerBatteryCurrent),
+		PVVoltage:      w.float(CodeSolarChargerPVVoltage),
+		PVPower:        w.float(CodeSolarChargerPVPower),
+		YieldToday:     w.float(CodeSolarChargerYieldToday),
+		YieldYesterday: w.float(CodeSolarChargerYieldYesterday),
+		State:          w.stateOf(CodeSolarChargerChargeState),
+		Error:          w.stateOf(CodeSolarChargerErrorCode),
+		Widget:         w,
+	}
+}
+
+// BMSSummary holds the cell limits and charge permissions reported by a battery management system.
+// Values not reported by the device are nil.
+type BMSSummary struct {
+	MinCellVoltage     *float64
+	MaxCellVoltage     *float64
+	MinCellTemperature *float64
+	MaxCellTemperature *float64
+	AllowedToCharge    *bool
+	AllowedToDischarge *bool
+	Widget             *WidgetResponse
+}
+
+// Retrieve the diagnostics of a battery management system instance
+func (s *vrmSession) BMSDiagnostics(siteID int, instance int) (*BMSSummary, error) {
+	return s.BMSDiagnosticsContext(context.Background(), siteID, instance)
+}
+
+// BMSDiagnosticsContext is like BMSDiagnostics but carries a context.
+func (s *vrmSession) BMSDiagnosticsContext(ctx context.Context, siteID int, instance int) (*BMSSummary, error) {
+	return s.bmsSummary(ctx, siteID, WidgetBMSDiagnostics, instance)
+}
+
+// Retrieve the state of a lithium battery's BMS instance
+func (s *vrmSession) LithiumBMS(siteID int, instance int) (*BMSSummary, error) {
+	return s.LithiumBMSContext(context.Background(), siteID, instance)
+}
+
+// LithiumBMSContext is like LithiumBMS but carries a context.
+func (s *vrmSession) LithiumBMSContext(ctx context.Context, siteID int, instance int) (*BMSSummary, error) {
+	return s.bmsSummary(ctx, siteID, WidgetLithiumBMS, instance)
+}
+
+func (s *vrmSession) bmsSummary(ctx context.Context, siteID int, name string, instance int) (*BMSSummary, error) {
+	w, err := s.WidgetContext(ctx, siteID, name, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.BMSSummary(), nil
+}
+
+// BMSSummary extracts the BMS values from the response of a WidgetBMSDiagnostics or WidgetLithiumBMS widget.
+func (w *WidgetResponse) BMSSummary() *BMSSummary {
+	return &BMSSummary{
+		MinCellVoltage:     w.float(CodeBatteryMonitorMinimumCellVoltage),
+		MaxCellVoltage:     w.float(CodeBatteryMonitorMaximumCellVoltage),
+		MinCellTemperature: w.float(CodeBatteryMonitorMinimumCellTemperature),
+		MaxCellTemperature: w.float(CodeBatteryMonitorMaximumCellTemperature),
+		AllowedToCharge:    w.flag(CodeBatteryMonitorAllowToCharge),
+		AllowedToDischarge: w.flag(CodeBatteryMonitorAllowToDischarge),
+		Widget:             w,
+	}
+}
+
+// PVInverterStatus holds the output of a PV inverter per phase. Phases not reported by the device are nil.
+type PVInverterStatus struct {
+	// Sum of the reported phases in W; nil if no phase is reported
+	Power   *float64
+	PowerL1 *float64
+	PowerL2 *float64
+	PowerL3 *float64
+	State   *DeviceState
+	Widget  *WidgetResponse
+}
+
+// Retrieve the status of a PV inverter instance
+func (s *vrmSession) PVInverterStatus(siteID int, instance int) (*PVInverterStatus, error) {
+	return s.PVInverterStatusContext(context.Background(), siteID, instance)
+}
+
+// PVInverterStatusContext is like PVInverterStatus but carries a context.
+func (s *vrmSession) PVInverterStatusContext(ctx context.Context, siteID int, instance int) (*PVInverterStatus, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetPVInverterStatus, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.PVInverterStatus(), nil
+}
+
+// PVInverterStatus extracts the PV inverter status from the response of a WidgetPVInverterStatus widget.
+func (w *WidgetResponse) PVInverterStatus() *PVInverterStatus {
+	status := PVInverterStatus{
+		PowerL1: w.float(CodePVInverterPowerL1),
+		PowerL2: w.float(CodePVInverterPowerL2),
+		PowerL3: w.float(CodePVInverterPowerL3),
+		State:   w.DeviceState(),
+		Widget:  w,
+	}
+	for _, phase := range []*float64{status.PowerL1, status.PowerL2, status.PowerL3} {
+		if phase == nil {
+			continue
+		}
+		if status.Power == nil {
+			status.Power = new(float64)
+		}
+		*status.Power += *phase
+	}
+	return &status
+}
+
+// Retrieve the overall status of an installation
+func (s *vrmSession) StatusWidget(siteID int) (*DeviceState, error) {
+	return s.StatusWidgetContext(context.Background(), siteID)
+}
+
+// StatusWidgetContext is like StatusWidget but carries a context.
+func (s *vrmSession) StatusWidgetContext(ctx context.Context, siteID int) (*DeviceState, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetStatus, WidgetQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.DeviceState(), nil
+}
+
+// MotorSummary holds the key values of an electric motor. Values not reported by the device are nil.
+type MotorSummary struct {
+	RPM         *float64
+	Temperature *float64
+	Power       *float64
+	Widget      *WidgetResponse
+}
+
+// Retrieve the summary of an electric motor instance
+func (s *vrmSession) MotorSummary(siteID int, instance int) (*MotorSummary, error) {
+	return s.MotorSummaryContext(context.Background(), siteID, instance)
+}
+
+// MotorSummaryContext is like MotorSummary but carries a context.
+func (s *vrmSession) MotorSummaryContext(ctx context.Context, siteID int, instance int) (*MotorSummary, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetMotorSummary, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.MotorSummary(), nil
+}
+
+// MotorSummary extracts the motor summary from the response of a WidgetMotorSummary widget.
+func (w *WidgetResponse) MotorSummary() *MotorSummary {
+	return &MotorSummary{
+		RPM:         w.float(CodeMotorDriveMotorRPM),
+		Temperature: w.float(CodeMotorDriveMotorTemperature),
+		Power:       w.float(CodeMotorDriveMotorPower),
+		Widget:      w,
+	}
+}
+
+// IOChannel is an input or output of an IO extender.
+type IOChannel struct {
+	// Description of the attribute, e.g. "Relay 1"
+	Name      string
+	Active    bool
+	Attribute WidgetAttribute
+}
+
+// IOExtenderInOut holds the inputs and outputs of an IO extender ordered by data attribute ID.
+type IOExtenderInOut struct {
+	Inputs  []IOChannel
+	Outputs []IOChannel
+	Widget  *WidgetResponse
+}
+
+// Retrieve the inputs and outputs of an IO extender instance
+func (s *vrmSession) IOExtenderInOut(siteID int, instance int) (*IOExtenderInOut, error) {
+	return s.IOExtenderInOutContext(context.Background(), siteID, instance)
+}
+
+// IOExtenderInOutContext is like IOExtenderInOut but carries a context.
+func (s *vrmSession) IOExtenderInOutContext(ctx context.Context, siteID int, instance int) (*IOExtenderInOut, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetIOExtenderInOut, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.IOExtenderInOut(), nil
+}
+
+// IOExtenderInOut extracts the channels from the response of a WidgetIOExtenderInOut widget. Attributes
+// described as output or relay are outputs, all others inputs.
+func (w *WidgetResponse) IOExtenderInOut() *IOExtenderInOut {
+	inOut := IOExtenderInOut{Widget: w}
+	for _, attr := range w.attributes() {
+		value, _ := attr.Float()
+		channel := IOChannel{Name: attr.Description, Active: value != 0, Attribute: attr}
+		description := strings.ToLower(attr.Description)
+		if strings.Contains(description, "output") || strings.Contains(description, "relay") {
+			inOut.Outputs = append(inOut.Outputs, channel)
+		} else {
+			inOut.Inputs = append(inOut.Inputs, channel)
+		}
+	}
+	return &inOut
+}
+
+// HistoricData holds the history of a battery monitor. Values not reported by the device are nil.
+type HistoricData struct {
+	// Discharges in Ah
+	DeepestDischarge *float64
+	LastDischarge    *float64
+	AverageDischarge *float64
+	ChargeCycles     *float64
+	FullDischarges   *float64
+	TotalAhDrawn     *float64
+	MinimumVoltage   *float64
+	MaximumVoltage   *float64
+	// Time since the last full charge in seconds
+	SinceFullCharge *float64
+	// Energies in kWh
+	DischargedEnergy *float64
+	ChargedEnergy    *float64
+	Widget           *WidgetResponse
+}
+
+// Retrieve the history of a battery monitor instance
+func (s *vrmSession) HistoricData(siteID int, instance int) (*HistoricData, error) {
+	return s.HistoricDataContext(context.Background(), siteID, instance)
+}
+
+// HistoricDataContext is like HistoricData but carries a context.
+func (s *vrmSession) HistoricDataContext(ctx context.Context, siteID int, instance int) (*HistoricData, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetHistoricData, WidgetQuery{Instance: &instance})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.HistoricData(), nil
+}
+
+// HistoricData extracts the battery history from the response of a WidgetHistoricData widget.
+func (w *WidgetResponse) HistoricData() *HistoricData {
+	return &HistoricData{
+		DeepestDischarge: w.float(CodeBatteryMonitorDeepestDischarge),
+		LastDischarge:    w.float(CodeBatteryMonitorLastDischarge),
+		AverageDischarge: w.float(CodeBatteryMonitorAverageDischarge),
+		ChargeCycles:     w.float(CodeBatteryMonitorChargeCycles),
+		FullDischarges:   w.float(CodeBatteryMonitorFullDischarges),
+		TotalAhDrawn:     w.float(CodeBatteryMonitorTotalAhDrawn),
+		MinimumVoltage:   w.float(CodeBatteryMonitorMinimumVoltage),
+		MaximumVoltage:   w.float(CodeBatteryMonitorMaximumVoltage),
+		SinceFullCharge:  w.float(CodeBatteryMonitorTimeSinceLastFullCharge),
+		DischargedEnergy: w.float(CodeBatteryMonitorDischargedEnergy),
+		ChargedEnergy:    w.float(CodeBatteryMonitorChargedEnergy),
+		Widget:           w,
+	}
+}
+
+// GPSPosition is the last position reported by a GPS. Values not reported by the device are nil.
+type GPSPosition struct {
+	// Time of the position; zero if unknown
+	Time      time.Time
+	Latitude  *float64
+	Longitude *float64
+	// Speed in m/s
+	Speed *float64
+	// Course in degrees
+	Course   *float64
+	Altitude *float64
+	Widget   *WidgetResponse
+}
+
+// Retrieve the last position of an installation's GPS
+func (s *vrmSession) GPS(siteID int) (*GPSPosition, error) {
+	return s.GPSContext(context.Background(), siteID)
+}
+
+// GPSContext is like GPS but carries a context.
+func (s *vrmSession) GPSContext(ctx context.Context, siteID int) (*GPSPosition, error) {
+	w, err := s.WidgetContext(ctx, siteID, WidgetGPS, WidgetQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.GPSPosition(), nil
+}
+
+// GPSPosition extracts the position from the response of a WidgetGPS widget.
+func (w *WidgetResponse) GPSPosition() *GPSPosition {
+	position := GPSPosition{
+		Latitude:  w.float(CodeGPSLatitude),
+		Longitude: w.float(CodeGPSLongitude),
+		Speed:     w.float(CodeGPSSpeed),
+		Course:    w.float(CodeGPSCourse),
+		Altitude:  w.float(CodeGPSAltitude),
+		Widget:    w,
+	}
+	if attr, ok := w.Attribute(CodeGPSLatitude); ok && attr.Timestamp > 0 {
+		position.Time = time.Unix(attr.Timestamp, 0)
+	}
+	return &position
+}
+
+// GraphMeta describes a series of a Graph widget.
+type GraphMeta struct {
+	Code            string `json:"code"`
+	Description     string `json:"description"`
+	FormatValueOnly string `json:"formatValueOnly"`
+	FormatWithUnit  string `json:"formatWithUnit"`
+}
+
+type GraphResponse struct {
+	Success bool `json:"success"`
+	Records struct {
+		// Series keyed by data attribute ID
+		Data StatsRecords         `json:"data"`
+		Meta map[string]GraphMeta `json:"meta"`
+	} `json:"records"`
+}
+
+// Series returns the series of the attribute with the given code.
+func (g *GraphResponse) Series(code string) (Series, bool) {
+	for id, meta := range g.Records.Meta {
+		if meta.Code == code {
+			series, ok := g.Records.Data[id]
+			return series, ok
+		}
+	}
+	return nil, false
+}
+
+// Retrieve time series of the attributes selected by the query's attribute codes or IDs
+func (s *vrmSession) Graph(siteID int, query WidgetQuery) (*GraphResponse, error) {
+	return s.GraphContext(context.Background(), siteID, query)
+}
+
+// GraphContext is like Graph but carries a context.
+func (s *vrmSession) GraphContext(ctx context.Context, siteID int, query WidgetQuery) (*GraphResponse, error) {
+	data := GraphResponse{}
+	if err := s.loadWidget(ctx, siteID, WidgetGraph, query, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,243 @@
+package vrm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	vrm "github.com/christianschmizz/go-victron"
+)
+
+func TestWidgets(t *testing.T) {
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/installations/1234/widgets/BatterySummary":
+			assert.Equal(t, "288", r.URL.Query().Get("instance"))
+			fmt.Fprint(w, `{"success": true, "records": {"data": {
+				"47": {"code": "bv", "idDataAttribute": 47, "valueFloat": 12.81, "formattedValue": "12.81 V"},
+				"51": {"code": "bs", "idDataAttribute": 51, "rawValue": "95.5", "formattedValue": "95.5 %"},
+				"hasOldData": false
+			}, "meta": {}}}`)
+		case "/installations/1234/widgets/MPPTState":
+			fmt.Fprint(w, `{"success": true, "records": {"data": {
+				"85": {"code": "ScS", "idDataAttribute": 85, "valueEnum": 3, "nameEnum": "Bulk"}
+			}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	battery, err := session.BatterySummary(1234, 288)
+	if assert.NoError(t, err) {
+		if assert.NotNil(t, battery.Voltage) {
+			assert.Equal(t, 12.81, *battery.Voltage)
+		}
+		if assert.NotNil(t, battery.StateOfCharge) {
+			assert.Equal(t, 95.5, *battery.StateOfCharge)
+		}
+		assert.Nil(t, battery.Current)
+		assert.Len(t, battery.Widget.Records.Attributes, 2)
+	}
+
+	state, err := session.MPPTState(1234, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, state.Value)
+		assert.Equal(t, "Bulk", state.Name)
+	}
+
+	_, err = session.Widget(1234, vrm.WidgetLithiumBMS, vrm.WidgetQuery{})
+	assert.True(t, vrm.IsNotFound(err))
+}
+
+func TestWidgetRecordsSchemaDrift(t *testing.T) {
+	w := vrm.WidgetResponse{}
+	err := json.Unmarshal([]byte(`{"success": true, "records": {"data": {
+		"47": {"code": "bv", "idDataAttribute": "forty-seven"},
+		"hasOldData": false
+	}}}`), &w)
+	assert.Error(t, err)
+}
+
+var widgetResponses = map[string]string{
+	"VeBusState": `{"success": true, "records": {"data": {
+		"40": {"code": "S", "idDataAttribute": 40, "valueEnum": 9, "nameEnum": "Inverting"}
+	}}}`,
+	"Alarm": `{"success": true, "records": {
+		"alarms": [{"idDataAttribute": 51, "instance": 288, "code": "bs", "description": "State of charge",
+			"formattedValue": "9.5 %", "started": 1603020000}],
+		"devices": [{"name": "Battery Monitor", "instance": 288}]
+	}}`,
+	"HoursOfAC": `{"success": true, "records": {"data": [[1602979200000, 6.5], [1603065600000, 24]]}}`,
+	"SolarChargerSummary": `{"success": true, "records": {"data": {
+		"81": {"code": "ScV", "idDataAttribute": 81, "valueFloat": 13.6},
+		"82": {"code": "PVP", "idDataAttribute": 82, "rawValue": "240"},
+		"85": {"code": "ScS", "idDataAttribute": 85, "valueEnum": 5, "nameEnum": "Float"},
+		"94": {"code": "YT", "idDataAttribute": 94, "valueFloat": 1.25},
+		"hasOldData": false
+	}}}`,
+	"BMSDiagnostics": `{"success": true, "records": {"data": {
+		"1": {"code": "mcV", "idDataAttribute": 1, "valueFloat": 3.31},
+		"2": {"code": "McV", "idDataAttribute": 2, "valueFloat": 3.35},
+		"3": {"code": "Bac", "idDataAttribute": 3, "valueFloat": 1},
+		"4": {"code": "Bad", "idDataAttribute": 4, "valueFloat": 0}
+	}}}`,
+	"PVInverterStatus": `{"success": true, "records": {"data": {
+		"1": {"code": "pP1", "idDataAttribute": 1, "valueFloat": 1000},
+		"2": {"code": "pP2", "idDataAttribute": 2, "valueFloat": 500},
+		"3": {"code": "pS", "idDataAttribute": 3, "valueEnum": 7, "nameEnum": "Running"}
+	}}}`,
+	"Status": `{"success": true, "records": {"data": {
+		"1": {"code": "st", "idDataAttribute": 1, "valueEnum": 0, "nameEnum": "OK"}
+	}}}`,
+	"MotorSummary": `{"success": true, "records": {"data": {
+		"1": {"code": "mr", "idDataAttribute": 1, "valueFloat": 1200},
+		"2": {"code": "mt", "idDataAttribute": 2, "valueFloat": 45}
+	}}}`,
+	"IOExtenderInOut": `{"success": true, "records": {"data": {
+		"1": {"code": "i1", "idDataAttribute": 1, "description": "Input 1", "valueFloat": 1},
+		"2": {"code": "r1", "idDataAttribute": 2, "description": "Relay 1", "valueFloat": 0}
+	}}}`,
+	"HistoricData": `{"success": true, "records": {"data": {
+		"1": {"code": "H1", "idDataAttribute": 1, "valueFloat": -120.5},
+		"2": {"code": "H4", "idDataAttribute": 2, "valueFloat": 212},
+		"3": {"code": "H7", "idDataAttribute": 3, "valueFloat": 11.8}
+	}}}`,
+	"GPS": `{"success": true, "records": {"data": {
+		"1": {"code": "lat", "idDataAttribute": 1, "valueFloat": 52.09, "timestamp": 1603020000},
+		"2": {"code": "lon", "idDataAttribute": 2, "valueFloat": 5.12},
+		"3": {"code": "sp", "idDataAttribute": 3, "valueFloat": 2.5}
+	}}}`,
+	"Graph": `{"success": true, "records": {
+		"data": {"51": [[1603020000000, 95.5, 95, 96], [1603023600000, 96]], "47": false},
+		"meta": {
+			"51": {"code": "bs", "description": "Battery SOC", "formatValueOnly": "%.1F", "formatWithUnit": "%.1F %%"},
+			"47": {"code": "bv", "description": "Battery voltage"}
+		}
+	}}`,
+}
+
+func TestTypedWidgets(t *testing.T) {
+	server := newDemoServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, ok := widgetResponses[strings.TrimPrefix(r.URL.Path, "/installations/1234/widgets/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, body)
+	})
+
+	session, err := vrm.LoginAsDemo(vrm.WithBaseURL(server.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	veBus, err := session.VeBusState(1234, 276)
+	if assert.NoError(t, err) {
+		assert.Equal(t, vrm.DeviceState{Value: 9, Name: "Inverting", Widget: veBus.Widget}, *veBus)
+	}
+
+	alarms, err := session.AlarmWidget(1234)
+	if assert.NoError(t, err) && assert.Len(t, alarms.Records.Alarms, 1) {
+		assert.Equal(t, "bs", alarms.Records.Alarms[0].Code)
+		assert.Equal(t, int64(1603020000), alarms.Records.Alarms[0].Started)
+		assert.Equal(t, "Battery Monitor", alarms.Records.Devices[0].Name)
+	}
+
+	start := time.Date(2020, 10, 18, 0, 0, 0, 0, time.UTC)
+	hours, err := session.HoursOfAC(1234, start, start.AddDate(0, 0, 2))
+	if assert.NoError(t, err) && assert.Len(t, hours.Records.Data, 2) {
+		assert.Equal(t, start, hours.Records.Data[0].Time.UTC())
+		assert.Equal(t, 30.5, hours.Records.Data.Sum())
+	}
+
+	solar, err := session.SolarChargerSummary(1234, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 13.6, *solar.BatteryVoltage)
+		assert.Equal(t, 240.0, *solar.PVPower)
+		assert.Equal(t, 1.25, *solar.YieldToday)
+		assert.Nil(t, solar.PVVoltage)
+		assert.Equal(t, "Float", solar.State.Name)
+		assert.Nil(t, solar.Error)
+	}
+
+	bms, err := session.BMSDiagnostics(1234, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3.31, *bms.MinCellVoltage)
+		assert.Equal(t, 3.35, *bms.MaxCellVoltage)
+		assert.True(t, *bms.AllowedToCharge)
+		assert.False(t, *bms.AllowedToDischarge)
+		assert.Nil(t, bms.MinCellTemperature)
+	}
+	_, err = session.LithiumBMS(1234, 0)
+	assert.True(t, vrm.IsNotFound(err))
+
+	pv, err := session.PVInverterStatus(1234, 20)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1500.0, *pv.Power)
+		assert.Nil(t, pv.PowerL3)
+		assert.Equal(t, "Running", pv.State.Name)
+	}
+
+	status, err := session.StatusWidget(1234)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, status.Value)
+		assert.Equal(t, "OK", status.Name)
+	}
+
+	motor, err := session.MotorSummary(1234, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1200.0, *motor.RPM)
+		assert.Equal(t, 45.0, *motor.Temperature)
+		assert.Nil(t, motor.Power)
+	}
+
+	io, err := session.IOExtenderInOut(1234, 0)
+	if assert.NoError(t, err) && assert.Len(t, io.Inputs, 1) && assert.Len(t, io.Outputs, 1) {
+		assert.Equal(t, "Input 1", io.Inputs[0].Name)
+		assert.True(t, io.Inputs[0].Active)
+		assert.Equal(t, "Relay 1", io.Outputs[0].Name)
+		assert.False(t, io.Outputs[0].Active)
+	}
+
+	history, err := session.HistoricData(1234, 288)
+	if assert.NoError(t, err) {
+		assert.Equal(t, -120.5, *history.DeepestDischarge)
+		assert.Equal(t, 212.0, *history.ChargeCycles)
+		assert.Equal(t, 11.8, *history.MinimumVoltage)
+		assert.Nil(t, history.ChargedEnergy)
+	}
+
+	position, err := session.GPS(1234)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 52.09, *position.Latitude)
+		assert.Equal(t, 5.12, *position.Longitude)
+		assert.Equal(t, 2.5, *position.Speed)
+		assert.Nil(t, position.Altitude)
+		assert.Equal(t, time.Unix(1603020000, 0), position.Time)
+	}
+
+	graph, err := session.Graph(1234, vrm.WidgetQuery{AttributeCodes: []string{"bs", "bv"}})
+	if assert.NoError(t, err) {
+		soc, ok := graph.Series("bs")
+		if assert.True(t, ok) && assert.Len(t, soc, 2) {
+			assert.Equal(t, 95.0, soc[0].Min)
+			assert.Equal(t, 96.0, soc[1].Value)
+		}
+		voltage, ok := graph.Series("bv")
+		assert.True(t, ok)
+		assert.Empty(t, voltage)
+		_, ok = graph.Series("bc")
+		assert.False(t, ok)
+		assert.Equal(t, "%.1F %%", graph.Records.Meta["51"].FormatWithUnit)
+	}
+}